@@ -17,12 +17,19 @@ import (
 	z19 "github.com/eternal-flame-AD/mh-z19"
 	"github.com/tarm/serial"
 	loggerFactory "github.com/walkure/homeprobe/pkg/logger"
+	"github.com/walkure/homeprobe/pkg/metrics"
 	"github.com/walkure/homeprobe/pkg/revision"
+	"github.com/walkure/homeprobe/pkg/selfmetrics"
+	"github.com/walkure/homeprobe/pkg/units"
 )
 
 var mhz19Addr = flag.String("mhz19", "", "MH-Z19 UART Port")
 var promAddr = flag.String("listen", ":9821", "OpenMetrics Exporter Listeing Address")
 var logLevel = flag.String("loglevel", "INFO", "Log Level")
+var relabeler = metrics.RegisterRelabelFlags(flag.CommandLine)
+var precisions = metrics.RegisterPrecisionFlag(flag.CommandLine)
+var naming = metrics.RegisterNamingFlag(flag.CommandLine)
+var runtimeMetrics = flag.Bool("runtime_metrics", false, "Expose Go runtime and process metrics")
 
 const warmingSeconds = 30
 
@@ -43,27 +50,45 @@ func main() {
 
 	start := time.Now().Add(warmingSeconds * time.Second)
 
-	http.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+	co2 := metrics.NewGauge("co2", "CO2 ppm",
+		metrics.WithRelabel(relabeler),
+		metrics.WithPrecisions(precisions),
+		metrics.WithNaming(*naming, units.StandardNames),
+	)
+	self := selfmetrics.New(metrics.WithRelabel(relabeler))
+	result := metrics.MetricSet{}
+	result.Add(co2)
+	result.Add(self.Metrics()...)
+	if *runtimeMetrics {
+		result.Add(metrics.NewRuntimeMetrics(metrics.WithRelabel(relabeler))...)
+	}
+
+	http.Handle("/metrics", self.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		if time.Now().Before(start) {
+			w.Header().Set("Content-Type", metrics.Negotiate(r.Header).ContentType())
 			w.WriteHeader(http.StatusServiceUnavailable)
-			fmt.Fprintln(w, "# HELP co2 CO2 ppm")
-			fmt.Fprintln(w, "# TYPE co2 gauge")
 			logger.Info("Warming up..", slog.String("leftSecs", time.Until(start).String()))
 			return
 		}
 
-		concentration, err := measureMHZ19()
+		var concentration uint16
+		err := self.Read("mhz19", func() (err error) {
+			concentration, err = measureMHZ19()
+			return err
+		})
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			logger.Error("measurement error", slog.Any("err", err))
 			io.WriteString(w, fmt.Sprintf("Error:%s\n", err.Error()))
 			return
 		}
-		fmt.Fprintln(w, "# HELP co2 CO2 ppm")
-		fmt.Fprintln(w, "# TYPE co2 gauge")
-		fmt.Fprintf(w, "co2{place=\"inside\"} %d\n", concentration)
-	})
+		co2.Set(
+			metrics.Labels{"place": "inside"},
+			metrics.Int64(concentration),
+		)
+		result.ServeHTTP(w, r)
+	})))
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
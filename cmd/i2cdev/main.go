@@ -14,7 +14,12 @@ import (
 	"time"
 
 	"github.com/walkure/go-lpsensors"
+	"github.com/walkure/homeprobe/pkg/indices"
+	"github.com/walkure/homeprobe/pkg/metrics"
 	"github.com/walkure/homeprobe/pkg/revision"
+	"github.com/walkure/homeprobe/pkg/selfmetrics"
+	"github.com/walkure/homeprobe/pkg/units"
+	"github.com/walkure/homeprobe/pkg/weather"
 	"periph.io/x/conn/v3/i2c/i2creg"
 	"periph.io/x/devices/v3/bmxx80"
 	"periph.io/x/devices/v3/ccs811"
@@ -25,6 +30,16 @@ var promAddr = flag.String("listen", ":9821", "OpenMetrics Exporter Listeing Add
 var tempOffset = flag.Float64("temp_offset", 0, "Temperature offset")
 var aboveSeaLevel = flag.Float64("above_sea_level", 0, "Height above sea level")
 var logLevel = flag.String("loglevel", "INFO", "Log Level")
+var relabeler = metrics.RegisterRelabelFlags(flag.CommandLine)
+var precisions = metrics.RegisterPrecisionFlag(flag.CommandLine)
+var naming = metrics.RegisterNamingFlag(flag.CommandLine)
+var outputUnits = units.RegisterFlags(flag.CommandLine)
+var thermalIndices = indices.RegisterFlag(flag.CommandLine)
+var vpdConfig = indices.RegisterVPDFlags(flag.CommandLine)
+var moldConfig = indices.RegisterMoldFlags(flag.CommandLine)
+var reduction = weather.RegisterReductionFlag(flag.CommandLine, weather.ReductionCurrent, weather.ReductionQNH)
+var psychrometrics = indices.RegisterPsychrometricsFlag(flag.CommandLine)
+var runtimeMetrics = flag.Bool("runtime_metrics", false, "Expose Go runtime and process metrics")
 
 const (
 	ccs811_bus      = 0x5b
@@ -121,7 +136,29 @@ func main() {
 
 	start := time.Now().Add(warming_seconds * time.Second)
 
-	http.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+	self := selfmetrics.New(metrics.WithRelabel(relabeler))
+	opts := []metrics.Option{
+		metrics.WithRelabel(relabeler),
+		metrics.WithPrecisions(precisions),
+		metrics.WithNaming(*naming, units.StandardNames),
+	}
+	mold, err := moldConfig.NewMold(opts...)
+	if err != nil {
+		logger.Error("mold index", slog.Any("err", err))
+		os.Exit(1)
+	}
+	// keeps the states over scrapes
+	h := &history{
+		tendency: indices.NewTendency(outputUnits, opts...),
+		reducer:  weather.NewSeaLevelReducer(*reduction, *aboveSeaLevel),
+		mold:     mold,
+	}
+	var runtimeSet []metrics.Metric
+	if *runtimeMetrics {
+		runtimeSet = metrics.NewRuntimeMetrics(metrics.WithRelabel(relabeler))
+	}
+
+	http.Handle("/metrics", self.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if time.Now().Before(start) {
 			w.WriteHeader(http.StatusServiceUnavailable)
 			fmt.Fprintln(w, "")
@@ -129,7 +166,7 @@ func main() {
 			return
 		}
 
-		result, err := measure(bmx, ccs, sht, lps)
+		result, err := measure(bmx, ccs, sht, lps, self, h, opts...)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			logger.Error("measurement error", slog.Any("err", err))
@@ -137,13 +174,19 @@ func main() {
 			return
 		}
 
-		result.Write(w)
+		result.Add(self.Metrics()...)
+		result.Add(runtimeSet...)
+		result.ServeHTTP(w, r)
 
-	})
+	})))
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	mold.StartSaver(ctx, 10*time.Minute, func(err error) {
+		logger.Error("mold state save", slog.Any("err", err))
+	})
+
 	serv := &http.Server{
 		Addr: *promAddr,
 	}
@@ -157,6 +200,10 @@ func main() {
 	}()
 	<-ctx.Done()
 
+	if err := mold.Save(); err != nil {
+		logger.Error("mold state save", slog.Any("err", err))
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	logger.Warn("shutting down server")
@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
+	"math"
+	"time"
 
 	//"log"
 
@@ -11,52 +13,84 @@ import (
 	"periph.io/x/devices/v3/ccs811"
 
 	"github.com/walkure/go-lpsensors"
+	"github.com/walkure/homeprobe/pkg/indices"
 	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/selfmetrics"
+	"github.com/walkure/homeprobe/pkg/units"
 	"github.com/walkure/homeprobe/pkg/weather"
 
 	sht3x "github.com/d2r2/go-sht3x"
 )
 
-func measure(bme *bmxx80.Dev, ccs *ccs811.Dev, sht *SHT3x, lps *lpsensors.Dev) (metrics.MetricSet, error) {
+// history keeps the states of derived quantities over scrapes
+type history struct {
+	tendency *indices.Tendency
+	reducer  *weather.SeaLevelReducer
+	mold     *indices.Mold
+}
+
+func measure(bme *bmxx80.Dev, ccs *ccs811.Dev, sht *SHT3x, lps *lpsensors.Dev, self *selfmetrics.Metrics, h *history, opts ...metrics.Option) (metrics.MetricSet, error) {
 
-	var inTemp, inHumid, hPaMSL float64
+	var inTemp, inHumid, hPa float64
 	var err error
 
 	s := metrics.MetricSet{}
-	temperature := metrics.NewGauge("temperature", "Temperature")
-	relativeHumidity := metrics.NewGauge("relative_humidity", "Relative Humidity percent")
-	absoluteHumidity := metrics.NewGauge("absolute_humidity", "Absolute Humidity g/m3")
-	disconfortIndex := metrics.NewGauge("disconfort_index", "Disconfort Index")
-	airPressure := metrics.NewGauge("pressure", "Air Pressure hPa")
-	eCO2ppm := metrics.NewGauge("eco2", "eCO2 ppm")
-	vocppb := metrics.NewGauge("voc", "VOC ppb")
-
-	s.Add(temperature)
+	temperature := outputUnits.NewGauge("temperature", "Temperature", units.Temperature, opts...)
+	relativeHumidity := metrics.NewGauge("relative_humidity", "Relative Humidity percent", opts...)
+	absoluteHumidity := outputUnits.NewGauge("absolute_humidity", "Absolute Humidity g/m3", units.AbsoluteHumidity, opts...)
+	dewPoint := outputUnits.NewGauge("dew_point", "Dew Point", units.Temperature, opts...)
+	disconfortIndex := metrics.NewGauge("disconfort_index", "Disconfort Index", opts...)
+	airPressure := outputUnits.NewGauge("pressure", "Air Pressure hPa", units.Pressure, opts...)
+	stationPressure := outputUnits.NewGauge("station_pressure", "Station Pressure (QFE) hPa", units.Pressure, opts...)
+	eCO2ppm := metrics.NewGauge("eco2", "eCO2 ppm", opts...)
+	vocppb := metrics.NewGauge("voc", "VOC ppb", opts...)
+	thermal := thermalIndices.NewGauges(outputUnits, opts...)
+	psychro := psychrometrics.NewGauges(outputUnits, opts...)
+	vpd := vpdConfig.NewVPD(opts...)
+	wbgt := metrics.NewGauge("wbgt_estimated", "Indoor WBGT estimated from temperature and humidity", opts...)
+	heatStrokeLevel := metrics.NewStateSet("heat_stroke_level", "Heat stroke risk level by estimated WBGT", weather.HeatStrokeLevelNames, opts...)
+
+	s.Add(temperature.Metrics()...)
 	s.Add(relativeHumidity)
-	s.Add(absoluteHumidity)
+	s.Add(absoluteHumidity.Metrics()...)
+	s.Add(dewPoint.Metrics()...)
 	s.Add(disconfortIndex)
-	s.Add(airPressure)
+	s.Add(airPressure.Metrics()...)
+	s.Add(stationPressure.Metrics()...)
 	s.Add(eCO2ppm)
 	s.Add(vocppb)
+	s.Add(thermal.Metrics()...)
+	s.Add(psychro.Metrics()...)
+	s.Add(vpd.Metrics()...)
+	s.Add(wbgt, heatStrokeLevel)
 
 	labels := metrics.Labels{"place": "inside"}
 
 	if bme != nil {
-		inTemp, inHumid, hPaMSL, err = measureBMxx80(bme)
+		err = self.Read("bmxx80", func() (err error) {
+			inTemp, inHumid, hPa, err = measureBMxx80(bme)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	if sht != nil {
-		inTemp, inHumid, err = measureSHT3x(sht)
+		err = self.Read("sht3x", func() (err error) {
+			inTemp, inHumid, err = measureSHT3x(sht)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	if lps != nil {
-		inTemp, hPaMSL, err = measureLPS(lps)
+		err = self.Read("lps331ap", func() (err error) {
+			inTemp, hPa, err = measureLPS(lps)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -71,6 +105,11 @@ func measure(bme *bmxx80.Dev, ccs *ccs811.Dev, sht *SHT3x, lps *lpsensors.Dev) (
 	)
 
 	if bme != nil || lps != nil {
+		humid := math.NaN()
+		if bme != nil || sht != nil {
+			humid = inHumid
+		}
+		hPaMSL := h.reducer.Reduce(time.Now(), hPa, inTemp, humid)
 		airPressure.Set(
 			labels,
 			metrics.RoundFloat64{
@@ -78,6 +117,17 @@ func measure(bme *bmxx80.Dev, ccs *ccs811.Dev, sht *SHT3x, lps *lpsensors.Dev) (
 				Precision: 2,
 			},
 		)
+
+		stationPressure.Set(
+			labels,
+			metrics.RoundFloat64{
+				Value:     hPa,
+				Precision: 2,
+			},
+		)
+
+		h.tendency.Add(labels, time.Now(), hPaMSL, time.Time{})
+		s.Add(h.tendency.Metrics()...)
 	}
 
 	if bme != nil || sht != nil {
@@ -97,6 +147,14 @@ func measure(bme *bmxx80.Dev, ccs *ccs811.Dev, sht *SHT3x, lps *lpsensors.Dev) (
 			},
 		)
 
+		dewPoint.Set(
+			labels,
+			metrics.RoundFloat64{
+				Value:     weather.DewPoint(inTemp, inHumid),
+				Precision: 2,
+			},
+		)
+
 		disconfortIndex.Set(
 			labels,
 			metrics.RoundFloat64{
@@ -104,11 +162,35 @@ func measure(bme *bmxx80.Dev, ccs *ccs811.Dev, sht *SHT3x, lps *lpsensors.Dev) (
 				Precision: 2,
 			},
 		)
+
+		thermal.Set(labels, inTemp, inHumid)
+		vpd.Set(labels, inTemp, inHumid)
+		h.mold.Set(labels, inTemp, inHumid)
+		s.Add(h.mold.Metrics()...)
+
+		pressure := weather.StandardPressure
+		if bme != nil || lps != nil {
+			pressure = hPa
+		}
+		psychro.SetWithPressure(labels, inTemp, inHumid, pressure, time.Time{})
+
+		wbgtValue := weather.IndoorWBGT(inTemp, inHumid)
+		wbgt.Set(
+			labels,
+			metrics.RoundFloat64{
+				Value:     wbgtValue,
+				Precision: 2,
+			},
+		)
+		heatStrokeLevel.SetState(labels, weather.HeatStrokeLevelOf(wbgtValue).String())
 	}
 
 	if ccs != nil {
 		var eCO2, voc float64
-		eCO2, voc, err = measureCCS811(inTemp, inHumid, ccs)
+		err = self.Read("ccs811", func() (err error) {
+			eCO2, voc, err = measureCCS811(inTemp, inHumid, ccs)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -142,9 +224,9 @@ func measureBMxx80(bme *bmxx80.Dev) (float64, float64, float64, error) {
 
 	inTemp := float64(temp.Celsius())
 	inHumid := float64(env.Humidity) / float64(physic.PercentRH)
-	hPaMSL := weather.MeanHeightAirPressure(float64(env.Pressure)/float64(physic.Pascal*100), inTemp, *aboveSeaLevel)
+	hPa := float64(env.Pressure) / float64(physic.Pascal*100)
 
-	return inTemp, inHumid, hPaMSL, nil
+	return inTemp, inHumid, hPa, nil
 }
 
 func measureCCS811(inTemp, inHumid float64, ccs *ccs811.Dev) (float64, float64, error) {
@@ -183,7 +265,7 @@ func measureLPS(lps *lpsensors.Dev) (float64, float64, error) {
 	//log.Printf("LPS331AP %s\n", env.String())
 
 	inTemp := float64(env.Temperature.Celsius())
-	hPaMSL := weather.MeanHeightAirPressure(float64(env.Pressure)/float64(physic.Pascal*100), inTemp, *aboveSeaLevel)
+	hPa := float64(env.Pressure) / float64(physic.Pascal*100)
 
-	return inTemp, hPaMSL, nil
+	return inTemp, hPa, nil
 }
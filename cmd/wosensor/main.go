@@ -13,9 +13,12 @@ import (
 
 	"github.com/walkure/gatt"
 	"github.com/walkure/go-wosensors"
+	"github.com/walkure/homeprobe/pkg/indices"
 	loggerFactory "github.com/walkure/homeprobe/pkg/logger"
 	"github.com/walkure/homeprobe/pkg/metrics"
 	"github.com/walkure/homeprobe/pkg/revision"
+	"github.com/walkure/homeprobe/pkg/selfmetrics"
+	"github.com/walkure/homeprobe/pkg/units"
 
 	"kernel.org/pub/linux/libs/security/libcap/cap"
 )
@@ -23,6 +26,16 @@ import (
 var promAddr = flag.String("listen", ":9821", "OpenMetrics Exporter Listeing Address")
 var logLevel = flag.String("loglevel", "INFO", "Log Level")
 var woSensorTHOId = flag.String("tho", "", "WoSensorTHO Device ID")
+var sampleTimestamp = flag.Bool("sample_timestamp", false, "Expose the time of advertisement as sample timestamp")
+var relabeler = metrics.RegisterRelabelFlags(flag.CommandLine)
+var precisions = metrics.RegisterPrecisionFlag(flag.CommandLine)
+var naming = metrics.RegisterNamingFlag(flag.CommandLine)
+var outputUnits = units.RegisterFlags(flag.CommandLine)
+var thermalIndices = indices.RegisterFlag(flag.CommandLine)
+var vpdConfig = indices.RegisterVPDFlags(flag.CommandLine)
+var moldConfig = indices.RegisterMoldFlags(flag.CommandLine)
+var runtimeMetrics = flag.Bool("runtime_metrics", false, "Expose Go runtime and process metrics")
+var lastUpdate = flag.Bool("last_update", false, "Expose the time of last update as <name>_last_update_timestamp_seconds")
 
 // name of binary file populated at build-time
 var binName = ""
@@ -42,9 +55,41 @@ func main() {
 	logger.Info("arguments",
 		slog.String("listen", *promAddr),
 		slog.String("tho", *woSensorTHOId),
+		slog.Bool("sampleTimestamp", *sampleTimestamp),
+		slog.Bool("lastUpdate", *lastUpdate),
+		slog.Bool("runtimeMetrics", *runtimeMetrics),
+		slog.String("naming", naming.String()),
+		slog.String("indices", thermalIndices.String()),
+		slog.Bool("vpd", vpdConfig.Enabled),
+		slog.Float64("leafTempOffset", vpdConfig.LeafOffset),
+		slog.Bool("moldIndex", moldConfig.Enabled),
+		slog.String("moldState", moldConfig.StatePath),
 	)
 
-	data := NewMetrics(15*time.Minute, metrics.Labels{"place": "outside"})
+	opts := []metrics.Option{
+		metrics.WithRelabel(relabeler),
+		metrics.WithPrecisions(precisions),
+		metrics.WithNaming(*naming, units.StandardNames),
+	}
+	if *sampleTimestamp {
+		opts = append(opts, metrics.WithTimestamp())
+	}
+	if *lastUpdate {
+		opts = append(opts, metrics.WithLastUpdate())
+	}
+	self := selfmetrics.New(metrics.WithRelabel(relabeler))
+	mold, err := moldConfig.NewMold(opts...)
+	if err != nil {
+		logger.Error("mold index", slog.Any("err", err))
+		os.Exit(1)
+	}
+	data := NewMetrics(15*time.Minute, metrics.Labels{"place": "outside"}, outputUnits, thermalIndices, vpdConfig, mold, self, opts...)
+	if *runtimeMetrics {
+		data.Add(metrics.NewRuntimeMetrics(metrics.WithRelabel(relabeler))...)
+	}
+	data.OnSensorLost(func(labels metrics.Labels) {
+		logger.Warn("sensor lost", labels.LogAttr())
+	})
 	tho := NewTHO(*woSensorTHOId, data)
 
 	if tho == nil {
@@ -77,13 +122,16 @@ func main() {
 	}
 
 	// register handler to DefaultServeMux
-	http.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
-		data.Write(w)
-	})
+	http.Handle("/metrics", data)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	data.StartReaper(ctx, time.Minute)
+	mold.StartSaver(ctx, 10*time.Minute, func(err error) {
+		logger.Error("mold state save", slog.Any("err", err))
+	})
+
 	go func() {
 		logger.Info("server listening", slog.String("address", serv.Addr))
 
@@ -93,6 +141,10 @@ func main() {
 	}()
 	<-ctx.Done()
 
+	if err := mold.Save(); err != nil {
+		logger.Error("mold state save", slog.Any("err", err))
+	}
+
 	d.StopScanning()
 	if err := d.Stop(); err != nil {
 		logger.Error("hci stop", slog.String("error", err.Error()))
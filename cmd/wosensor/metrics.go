@@ -1,46 +1,96 @@
 package main
 
 import (
+	"context"
 	"io"
 	"maps"
+	"net/http"
 	"time"
 
+	"github.com/walkure/homeprobe/pkg/indices"
 	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/selfmetrics"
+	"github.com/walkure/homeprobe/pkg/units"
+	"github.com/walkure/homeprobe/pkg/weather"
 )
 
 type MetricData struct {
-	temp            metrics.Metric
+	temp            *units.Gauge
 	relHumid        metrics.Metric
-	absHumid        metrics.Metric
+	absHumid        *units.Gauge
+	dewPoint        *units.Gauge
 	disconfortIndex metrics.Metric
 	vBattery        metrics.Metric
+	sensorInfo      metrics.Info
+	thermal         *indices.Gauges
+	vpd             *indices.VPD
+	mold            *indices.Mold
+	wbgt            metrics.Metric
+	heatStrokeLevel metrics.StateSet
+	self            *selfmetrics.Metrics
 	ttl             time.Duration
 	baseLabels      metrics.Labels
-	d               metrics.MetricSet
+	d               *metrics.Registry
 }
 
-func NewMetrics(ttl time.Duration, baseLabels metrics.Labels) *MetricData {
+func NewMetrics(ttl time.Duration, baseLabels metrics.Labels, sel units.Selection, thermal indices.Selection, vpd *indices.VPDConfig, mold *indices.Mold, self *selfmetrics.Metrics, opts ...metrics.Option) *MetricData {
 	m := &MetricData{
-		temp:            metrics.NewGauge("temperature", "Temperature"),
-		relHumid:        metrics.NewGauge("relative_humidity", "Relative Humidity percent"),
-		absHumid:        metrics.NewGauge("absolute_humidity", "Absolute Humidity g/m^3"),
-		disconfortIndex: metrics.NewGauge("disconfort_index", "Disconfort Index"),
-		vBattery:        metrics.NewGauge("sensor_vbat", "Voltage of Sensor battery"),
+		temp:            sel.NewGauge("temperature", "Temperature", units.Temperature, opts...),
+		relHumid:        metrics.NewGauge("relative_humidity", "Relative Humidity percent", opts...),
+		absHumid:        sel.NewGauge("absolute_humidity", "Absolute Humidity g/m^3", units.AbsoluteHumidity, opts...),
+		dewPoint:        sel.NewGauge("dew_point", "Dew Point", units.Temperature, opts...),
+		disconfortIndex: metrics.NewGauge("disconfort_index", "Disconfort Index", opts...),
+		vBattery:        metrics.NewGauge("sensor_vbat", "Voltage of Sensor battery", opts...),
+		sensorInfo:      metrics.NewInfo("sensor", "Sensor device information", opts...),
+		thermal:         thermal.NewGauges(sel, opts...),
+		vpd:             vpd.NewVPD(opts...),
+		mold:            mold,
+		wbgt:            metrics.NewGauge("wbgt_estimated", "Indoor WBGT estimated from temperature and humidity", opts...),
+		heatStrokeLevel: metrics.NewStateSet("heat_stroke_level", "Heat stroke risk level by estimated WBGT", weather.HeatStrokeLevelNames, opts...),
+		self:            self,
 		ttl:             ttl,
 		baseLabels:      baseLabels,
 	}
 
-	d := metrics.MetricSet{}
-	d.Add(m.temp, m.relHumid, m.absHumid, m.disconfortIndex, m.vBattery)
+	d := metrics.NewRegistry()
+	d.Add(m.relHumid, m.disconfortIndex, m.vBattery, m.sensorInfo, m.wbgt, m.heatStrokeLevel)
+	d.Add(m.temp.Metrics()...)
+	d.Add(m.absHumid.Metrics()...)
+	d.Add(m.dewPoint.Metrics()...)
+	d.Add(m.thermal.Metrics()...)
+	d.Add(m.vpd.Metrics()...)
+	d.Add(mold.Metrics()...)
+	d.Add(self.Metrics()...)
 	m.d = d
 
 	return m
 }
 
+// Add adds metrics other than sensor values, e.g. runtime metrics
+func (m *MetricData) Add(ms ...metrics.Metric) {
+	m.d.Add(ms...)
+}
+
 func (m *MetricData) Write(w io.Writer) error {
 	return m.d.Write(w)
 }
 
+func (m *MetricData) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.self.Handler(m.d).ServeHTTP(w, r)
+}
+
+// StartReaper removes expired values in background until ctx is done
+func (m *MetricData) StartReaper(ctx context.Context, interval time.Duration) {
+	m.d.StartReaper(ctx, interval)
+}
+
+// OnSensorLost registers fn called when no data is received from a sensor within ttl
+func (m *MetricData) OnSensorLost(fn func(labels metrics.Labels)) {
+	m.sensorInfo.OnExpire(func(_ string, labels metrics.Labels) {
+		fn(labels)
+	})
+}
+
 func mergeLabels(base, extra metrics.Labels) metrics.Labels {
 	if extra == nil {
 		return base
@@ -86,6 +136,47 @@ func (m *MetricData) UpdateAbsoluteHumidity(value float64, extra metrics.Labels)
 	)
 }
 
+func (m *MetricData) UpdateDewPoint(value float64, extra metrics.Labels) {
+	m.dewPoint.SetWithTimeout(
+		mergeLabels(m.baseLabels, extra),
+		metrics.RoundFloat64{
+			Value:     value,
+			Precision: 2,
+		},
+		time.Now().Add(m.ttl),
+	)
+}
+
+// UpdateThermalIndices sets the selected indices by temperature and relative humidity
+func (m *MetricData) UpdateThermalIndices(temp, relativeHumid float64, extra metrics.Labels) {
+	m.thermal.SetWithTimeout(mergeLabels(m.baseLabels, extra), temp, relativeHumid, time.Now().Add(m.ttl))
+}
+
+// UpdateMold updates the mold growth model by temperature and relative humidity
+func (m *MetricData) UpdateMold(temp, relativeHumid float64, extra metrics.Labels) {
+	m.mold.Update(mergeLabels(m.baseLabels, extra), time.Now(), temp, relativeHumid, time.Now().Add(m.ttl))
+}
+
+// UpdateVPD sets the vapor pressure deficit by temperature and relative humidity
+func (m *MetricData) UpdateVPD(temp, relativeHumid float64, extra metrics.Labels) {
+	m.vpd.SetWithTimeout(mergeLabels(m.baseLabels, extra), temp, relativeHumid, time.Now().Add(m.ttl))
+}
+
+// UpdateWBGT sets the estimated WBGT and its heat stroke risk level
+func (m *MetricData) UpdateWBGT(value float64, extra metrics.Labels) {
+	labels := mergeLabels(m.baseLabels, extra)
+	expireAt := time.Now().Add(m.ttl)
+	m.wbgt.SetWithTimeout(
+		labels,
+		metrics.RoundFloat64{
+			Value:     value,
+			Precision: 2,
+		},
+		expireAt,
+	)
+	m.heatStrokeLevel.SetStateWithTimeout(labels, weather.HeatStrokeLevelOf(value).String(), expireAt)
+}
+
 func (m *MetricData) UpdateDisconfortIndex(value float64, extra metrics.Labels) {
 	m.disconfortIndex.SetWithTimeout(
 		mergeLabels(m.baseLabels, extra),
@@ -107,3 +198,11 @@ func (m *MetricData) UpdateBattery(value uint8, extra metrics.Labels) {
 		time.Now().Add(m.ttl),
 	)
 }
+
+func (m *MetricData) UpdateSensorInfo(model string, extra metrics.Labels) {
+	m.sensorInfo.SetInfoWithTimeout(
+		mergeLabels(m.baseLabels, extra),
+		metrics.Labels{"model": model},
+		time.Now().Add(m.ttl),
+	)
+}
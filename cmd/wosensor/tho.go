@@ -46,6 +46,8 @@ func (t *THO) Handler(next func(gatt.Peripheral, *gatt.Advertisement, int)) func
 		t.mu.Lock()
 		defer t.mu.Unlock()
 
+		t.m.self.AdvertisementReceived(t.deviceId)
+
 		if d.BatteryPercent <= 100 && t.bt_seqno != d.SequenceNumber {
 			t.bt_seqno = d.SequenceNumber
 			t.m.UpdateBattery(d.BatteryPercent, labels)
@@ -55,6 +57,7 @@ func (t *THO) Handler(next func(gatt.Peripheral, *gatt.Advertisement, int)) func
 		}
 
 		if t.seqno == d.SequenceNumber {
+			t.m.self.AdvertisementIgnored(t.deviceId)
 			t.logger.Debug("sequence not changed",
 				slog.Uint64("seq", uint64(d.SequenceNumber)),
 			)
@@ -65,10 +68,16 @@ func (t *THO) Handler(next func(gatt.Peripheral, *gatt.Advertisement, int)) func
 
 		t.logger.Info("data updated", "", d, "seq", d.SequenceNumber)
 
+		t.m.UpdateSensorInfo("WoSensorTHO", labels)
 		t.m.UpdateTemperature(float64(d.Temperature), labels)
 		t.m.UpdateRelativeHumidity(float64(d.Humidity), labels)
 		t.m.UpdateAbsoluteHumidity(weather.AbsoluteHumidity(float64(d.Temperature), float64(d.Humidity)), labels)
+		t.m.UpdateDewPoint(weather.DewPoint(float64(d.Temperature), float64(d.Humidity)), labels)
 		t.m.UpdateDisconfortIndex(weather.DisconfortIndex(float64(d.Temperature), float64(d.Humidity)), labels)
+		t.m.UpdateWBGT(weather.IndoorWBGT(float64(d.Temperature), float64(d.Humidity)), labels)
+		t.m.UpdateThermalIndices(float64(d.Temperature), float64(d.Humidity), labels)
+		t.m.UpdateVPD(float64(d.Temperature), float64(d.Humidity), labels)
+		t.m.UpdateMold(float64(d.Temperature), float64(d.Humidity), labels)
 
 	}
 
@@ -13,8 +13,13 @@ import (
 
 	"github.com/walkure/gatt"
 	"github.com/walkure/go-wxbeacon2"
+	"github.com/walkure/homeprobe/pkg/indices"
 	loggerFactory "github.com/walkure/homeprobe/pkg/logger"
+	"github.com/walkure/homeprobe/pkg/metrics"
 	"github.com/walkure/homeprobe/pkg/revision"
+	"github.com/walkure/homeprobe/pkg/selfmetrics"
+	"github.com/walkure/homeprobe/pkg/units"
+	"github.com/walkure/homeprobe/pkg/weather"
 
 	"kernel.org/pub/linux/libs/security/libcap/cap"
 )
@@ -23,6 +28,17 @@ var promAddr = flag.String("listen", ":9821", "OpenMetrics Exporter Listeing Add
 var aboveSeaLevel = flag.Float64("above_sea_level", 0, "Height above sea level")
 var wxBeacon2ID = flag.String("wxbeacon", "", "WxBeacon2 Device ID")
 var logLevel = flag.String("loglevel", "INFO", "Log Level")
+var sampleTimestamp = flag.Bool("sample_timestamp", false, "Expose the time of advertisement as sample timestamp")
+var relabeler = metrics.RegisterRelabelFlags(flag.CommandLine)
+var precisions = metrics.RegisterPrecisionFlag(flag.CommandLine)
+var naming = metrics.RegisterNamingFlag(flag.CommandLine)
+var outputUnits = units.RegisterFlags(flag.CommandLine)
+var thermalIndices = indices.RegisterFlag(flag.CommandLine)
+var vpdConfig = indices.RegisterVPDFlags(flag.CommandLine)
+var reduction = weather.RegisterReductionFlag(flag.CommandLine)
+var psychrometrics = indices.RegisterPsychrometricsFlag(flag.CommandLine)
+var runtimeMetrics = flag.Bool("runtime_metrics", false, "Expose Go runtime and process metrics")
+var lastUpdate = flag.Bool("last_update", false, "Expose the time of last update as <name>_last_update_timestamp_seconds")
 
 // name of binary file populated at build-time
 var binName = ""
@@ -47,9 +63,36 @@ func main() {
 		slog.String("listen", *promAddr),
 		slog.String("wxBeacon", *wxBeacon2ID),
 		slog.Float64("aboveSeaLevel", *aboveSeaLevel),
+		slog.String("reduction", reduction.String()),
+		slog.Bool("sampleTimestamp", *sampleTimestamp),
+		slog.Bool("lastUpdate", *lastUpdate),
+		slog.Bool("runtimeMetrics", *runtimeMetrics),
+		slog.String("naming", naming.String()),
+		slog.String("indices", thermalIndices.String()),
+		slog.Bool("vpd", vpdConfig.Enabled),
+		slog.Float64("leafTempOffset", vpdConfig.LeafOffset),
+		slog.String("psychrometrics", psychrometrics.String()),
 	)
 
-	metrics := initEnvData()
+	opts := []metrics.Option{
+		metrics.WithRelabel(relabeler),
+		metrics.WithPrecisions(precisions),
+		metrics.WithNaming(*naming, units.StandardNames),
+	}
+	if *sampleTimestamp {
+		opts = append(opts, metrics.WithTimestamp())
+	}
+	if *lastUpdate {
+		opts = append(opts, metrics.WithLastUpdate())
+	}
+	self := selfmetrics.New(metrics.WithRelabel(relabeler))
+	envData := initEnvData(self, opts...)
+	if *runtimeMetrics {
+		envData.Add(metrics.NewRuntimeMetrics(metrics.WithRelabel(relabeler))...)
+	}
+	wxbeaconData.sensorInfo.OnExpire(func(_ string, labels metrics.Labels) {
+		logger.Warn("sensor lost", labels.LogAttr())
+	})
 
 	// Passive scanning
 	d, err := gatt.NewDevice(gatt.LnxSetScanMode(false))
@@ -77,13 +120,13 @@ func main() {
 	}
 
 	// register handler to DefaultServeMux
-	http.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
-		metrics.Write(w)
-	})
+	http.Handle("/metrics", self.Handler(envData))
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	envData.StartReaper(ctx, time.Minute)
+
 	go func() {
 		logger.Info("server listening", slog.String("address", serv.Addr))
 
@@ -8,47 +8,78 @@ import (
 	"time"
 
 	"github.com/walkure/go-wxbeacon2"
+	"github.com/walkure/homeprobe/pkg/indices"
 	loggerFactory "github.com/walkure/homeprobe/pkg/logger"
 	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/selfmetrics"
+	"github.com/walkure/homeprobe/pkg/units"
 	"github.com/walkure/homeprobe/pkg/weather"
 )
 
 var lastSeqID = atomic.Uint32{}
 
 type envData struct {
-	temp            metrics.Metric
+	temp            *units.Gauge
 	relHumid        metrics.Metric
-	absHumid        metrics.Metric
+	absHumid        *units.Gauge
+	dewPoint        *units.Gauge
 	ambientLight    metrics.Metric
 	uvIndex         metrics.Metric
-	pressure        metrics.Metric
+	pressure        *units.Gauge
+	stationPressure *units.Gauge
+	reducer         *weather.SeaLevelReducer
 	soundNoise      metrics.Metric
 	disconfortIndex metrics.Metric
 	heatStoke       metrics.Metric
 	vBattery        metrics.Metric
+	sensorInfo      metrics.Info
+	thermal         *indices.Gauges
+	psychro         *indices.Gauges
+	vpd             *indices.VPD
+	tendency        *indices.Tendency
+	self            *selfmetrics.Metrics
 }
 
 var wxbeaconData *envData
 
-func initEnvData() metrics.MetricSet {
+func initEnvData(self *selfmetrics.Metrics, opts ...metrics.Option) *metrics.Registry {
 
 	wxbeaconData = &envData{
-		temp:            metrics.NewGauge("temperature", "Temperature"),
-		relHumid:        metrics.NewGauge("relative_humidity", "Relative Humidity percent"),
-		absHumid:        metrics.NewGauge("absolute_humidity", "Absolute Humidity g/m^3"),
-		ambientLight:    metrics.NewGauge("ambient_light", "Ambient Light lx"),
-		uvIndex:         metrics.NewGauge("uv_index", "Index of UV"),
-		pressure:        metrics.NewGauge("pressure", "Pressure hPa"),
-		soundNoise:      metrics.NewGauge("sound_noise", "Sound Noise db"),
-		disconfortIndex: metrics.NewGauge("disconfort_index", "Disconfort Index"),
-		heatStoke:       metrics.NewGauge("heat_stroke", "WGBT"),
-		vBattery:        metrics.NewGauge("sensor_vbat", "Voltage of Sensor battery"),
+		temp:            outputUnits.NewGauge("temperature", "Temperature", units.Temperature, opts...),
+		relHumid:        metrics.NewGauge("relative_humidity", "Relative Humidity percent", opts...),
+		absHumid:        outputUnits.NewGauge("absolute_humidity", "Absolute Humidity g/m^3", units.AbsoluteHumidity, opts...),
+		dewPoint:        outputUnits.NewGauge("dew_point", "Dew Point", units.Temperature, opts...),
+		ambientLight:    metrics.NewGauge("ambient_light", "Ambient Light lx", opts...),
+		uvIndex:         metrics.NewGauge("uv_index", "Index of UV", opts...),
+		pressure:        outputUnits.NewGauge("pressure", "Pressure hPa", units.Pressure, opts...),
+		stationPressure: outputUnits.NewGauge("station_pressure", "Station Pressure (QFE) hPa", units.Pressure, opts...),
+		reducer:         weather.NewSeaLevelReducer(*reduction, *aboveSeaLevel),
+		soundNoise:      metrics.NewGauge("sound_noise", "Sound Noise db", opts...),
+		disconfortIndex: metrics.NewGauge("disconfort_index", "Disconfort Index", opts...),
+		heatStoke:       metrics.NewGauge("heat_stroke", "WGBT", opts...),
+		vBattery:        metrics.NewGauge("sensor_vbat", "Voltage of Sensor battery", opts...),
+		sensorInfo:      metrics.NewInfo("sensor", "Sensor device information", opts...),
+		thermal:         thermalIndices.NewGauges(outputUnits, opts...),
+		psychro:         psychrometrics.NewGauges(outputUnits, opts...),
+		vpd:             vpdConfig.NewVPD(opts...),
+		tendency:        indices.NewTendency(outputUnits, opts...),
+		self:            self,
 	}
 
-	s := metrics.MetricSet{}
-	s.Add(wxbeaconData.absHumid, wxbeaconData.ambientLight, wxbeaconData.disconfortIndex,
-		wxbeaconData.heatStoke, wxbeaconData.pressure, wxbeaconData.relHumid, wxbeaconData.soundNoise,
-		wxbeaconData.temp, wxbeaconData.uvIndex, wxbeaconData.vBattery)
+	s := metrics.NewRegistry()
+	s.Add(wxbeaconData.ambientLight, wxbeaconData.disconfortIndex,
+		wxbeaconData.heatStoke, wxbeaconData.relHumid, wxbeaconData.soundNoise,
+		wxbeaconData.uvIndex, wxbeaconData.vBattery, wxbeaconData.sensorInfo)
+	s.Add(wxbeaconData.temp.Metrics()...)
+	s.Add(wxbeaconData.pressure.Metrics()...)
+	s.Add(wxbeaconData.stationPressure.Metrics()...)
+	s.Add(wxbeaconData.absHumid.Metrics()...)
+	s.Add(wxbeaconData.dewPoint.Metrics()...)
+	s.Add(wxbeaconData.thermal.Metrics()...)
+	s.Add(wxbeaconData.psychro.Metrics()...)
+	s.Add(wxbeaconData.vpd.Metrics()...)
+	s.Add(wxbeaconData.tendency.Metrics()...)
+	s.Add(self.Metrics()...)
 
 	return s
 }
@@ -65,9 +96,11 @@ func wxDataCallback(d wxbeacon2.WxData) {
 	}
 
 	logger := loggerFactory.GetLogger("wxcallback")
+	wxbeaconData.self.AdvertisementReceived(*wxBeacon2ID)
 
 	if lastSeqID.CompareAndSwap(uint32(data.Sequence), uint32(data.Sequence)) {
 		// sequence not changed.
+		wxbeaconData.self.AdvertisementIgnored(*wxBeacon2ID)
 		logger.Debug("sequence not changed", slog.Uint64("seq", uint64(data.Sequence)))
 		return
 	}
@@ -132,6 +165,16 @@ func (m *envData) setData(data wxbeacon2.WxEPData) {
 		expireAt,
 	)
 
+	m.sensorInfo.SetInfoWithTimeout(
+		labels,
+		metrics.Labels{
+			"device_id": data.DeviceId,
+			"model":     "2JCIE-BL01",
+			"mode":      "EP",
+		},
+		expireAt,
+	)
+
 	if dataError {
 		return
 	}
@@ -145,6 +188,19 @@ func (m *envData) setData(data wxbeacon2.WxEPData) {
 		expireAt,
 	)
 
+	m.dewPoint.SetWithTimeout(
+		labels,
+		metrics.RoundFloat64{
+			Value:     weather.DewPoint(data.Temp, data.Humid),
+			Precision: 2,
+		},
+		expireAt,
+	)
+
+	m.thermal.SetWithTimeout(labels, data.Temp, data.Humid, expireAt)
+	m.psychro.SetWithPressure(labels, data.Temp, data.Humid, data.Pressure, expireAt)
+	m.vpd.SetWithTimeout(labels, data.Temp, data.Humid, expireAt)
+
 	m.ambientLight.SetWithTimeout(
 		labels,
 		metrics.RoundFloat64{
@@ -163,14 +219,24 @@ func (m *envData) setData(data wxbeacon2.WxEPData) {
 		expireAt,
 	)
 
+	seaLevelPressure := m.reducer.Reduce(time.Now(), data.Pressure, data.Temp, data.Humid)
 	m.pressure.SetWithTimeout(
 		labels,
 		metrics.RoundFloat64{
-			Value:     weather.MeanHeightAirPressure(data.Pressure, data.Temp, *aboveSeaLevel),
+			Value:     seaLevelPressure,
+			Precision: 2,
+		},
+		expireAt,
+	)
+	m.stationPressure.SetWithTimeout(
+		labels,
+		metrics.RoundFloat64{
+			Value:     data.Pressure,
 			Precision: 2,
 		},
 		expireAt,
 	)
+	m.tendency.Add(labels, time.Now(), seaLevelPressure, expireAt)
 
 	m.soundNoise.SetWithTimeout(
 		labels,
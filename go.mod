@@ -7,8 +7,11 @@ require (
 	github.com/d2r2/go-logger v0.0.0-20210606094344-60e9d1233e22
 	github.com/d2r2/go-sht3x v0.0.0-20181222062132-074abc261905
 	github.com/eternal-flame-AD/mh-z19 v0.0.0-20190331151235-afa8347325ff
+	github.com/prometheus/client_golang v1.22.0
+	github.com/prometheus/client_model v0.6.2
 	github.com/tarm/serial v0.0.0-20180830185346-98f6abe2eb07
 	github.com/walkure/go-wxbeacon2 v0.0.0-20241025142600-7c706a4ce47b
+	google.golang.org/protobuf v1.36.9
 	kernel.org/pub/linux/libs/security/libcap/cap v1.2.49
 	periph.io/x/conn/v3 v3.7.1
 	periph.io/x/devices/v3 v3.6.9
@@ -16,11 +19,17 @@ require (
 )
 
 require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/d2r2/go-shell v0.0.0-20211022052110-f591c27e3e2e // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/kylelemons/godebug v1.1.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/common v0.62.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
 	github.com/walkure/gatt v0.0.0-20241018150429-9186a4bfc57d // indirect
 	github.com/walkure/go-lpsensors v0.0.0-20241027074002-d589b54e7609 // indirect
 	github.com/walkure/go-wosensors v0.0.0-20241027161104-ff90779971a2 // indirect
-	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
 	kernel.org/pub/linux/libs/security/libcap/psx v1.2.49 // indirect
 )
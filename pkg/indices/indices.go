@@ -0,0 +1,185 @@
+// Package indices emits the quantities derived by pkg/weather from temperature,
+// relative humidity and pressure, e.g. thermal indices and pressure tendency.
+package indices
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/units"
+	"github.com/walkure/homeprobe/pkg/util"
+	"github.com/walkure/homeprobe/pkg/weather"
+)
+
+// names of thermal indices, used as metric names
+const (
+	HeatIndex           = "heat_index"
+	Humidex             = "humidex"
+	ApparentTemperature = "apparent_temperature"
+)
+
+// names of psychrometric quantities, used as metric names
+const (
+	VaporPressure    = "vapor_pressure"
+	MixingRatio      = "mixing_ratio"
+	SpecificHumidity = "specific_humidity"
+	Enthalpy         = "enthalpy"
+	WetBulb          = "wet_bulb"
+)
+
+// index is a quantity derived from temperature in celsius, relative humidity and pressure in hectopascals
+type index struct {
+	name string
+	help string
+	// quantity follows the selected units if unit is set
+	unit     bool
+	quantity units.Quantity
+	value    func(temp, relativeHumid, pressure float64) float64
+}
+
+var thermalIndices = []index{
+	{
+		name: HeatIndex, help: "Heat Index (NOAA)", unit: true, quantity: units.Temperature,
+		value: func(temp, relativeHumid, _ float64) float64 {
+			return weather.HeatIndex(temp, relativeHumid)
+		},
+	},
+	{
+		name: Humidex, help: "Humidex (Environment Canada)",
+		value: func(temp, relativeHumid, _ float64) float64 {
+			return weather.Humidex(temp, relativeHumid)
+		},
+	},
+	{
+		name: ApparentTemperature, help: "Apparent Temperature (Steadman, without wind)", unit: true, quantity: units.Temperature,
+		value: func(temp, relativeHumid, _ float64) float64 {
+			// no anemometer on the probes
+			return weather.ApparentTemperature(temp, relativeHumid, 0)
+		},
+	},
+}
+
+var psychrometricQuantities = []index{
+	{name: VaporPressure, help: "Vapor Pressure", unit: true, quantity: units.Pressure, value: weather.VaporPressureAt},
+	{name: MixingRatio, help: "Mixing Ratio g/kg", value: weather.MixingRatio},
+	{name: SpecificHumidity, help: "Specific Humidity g/kg", value: weather.SpecificHumidity},
+	{name: Enthalpy, help: "Enthalpy of moist air kJ/kg", value: weather.Enthalpy},
+	{name: WetBulb, help: "Wet-bulb Temperature", unit: true, quantity: units.Temperature, value: weather.WetBulb},
+}
+
+func indexNames(indices []index) string {
+	var names []string
+	for _, i := range indices {
+		names = append(names, i.name)
+	}
+	return strings.Join(names, ",")
+}
+
+// Selection is a set of indices to emit
+type Selection map[string]bool
+
+// selectionFlag parses comma separated names of known indices
+type selectionFlag struct {
+	s     Selection
+	known []index
+}
+
+func (f selectionFlag) String() string {
+	if f.s == nil {
+		return ""
+	}
+	return f.s.String()
+}
+
+func (f selectionFlag) Set(v string) error {
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.ContainsFunc(f.known, func(i index) bool { return i.name == name }) {
+			return fmt.Errorf("unknown index: %q (%s)", name, indexNames(f.known))
+		}
+		f.s[name] = true
+	}
+	return nil
+}
+
+func (s Selection) String() string {
+	return strings.Join(util.Keys(s), ",")
+}
+
+// RegisterFlag registers flag `indices` to fs, and returns the selection of thermal indices configured by it.
+func RegisterFlag(fs *flag.FlagSet) Selection {
+	s := Selection{}
+	fs.Var(selectionFlag{s, thermalIndices}, "indices", "Comma separated indices to expose: "+indexNames(thermalIndices))
+	return s
+}
+
+// RegisterPsychrometricsFlag registers flag `psychrometrics` to fs, and returns the selection
+// of psychrometric quantities configured by it.
+func RegisterPsychrometricsFlag(fs *flag.FlagSet) Selection {
+	s := Selection{}
+	fs.Var(selectionFlag{s, psychrometricQuantities}, "psychrometrics", "Comma separated psychrometric quantities to expose: "+indexNames(psychrometricQuantities))
+	return s
+}
+
+// Gauges sets the selected indices
+type Gauges struct {
+	gauges []*units.Gauge
+	values []func(temp, relativeHumid, pressure float64) float64
+}
+
+// NewGauges creates gauges of the selected indices. Indices in celsius or hectopascals
+// follow the units of temperature or pressure.
+func (s Selection) NewGauges(u units.Selection, opts ...metrics.Option) *Gauges {
+	g := &Gauges{}
+	for _, i := range slices.Concat(thermalIndices, psychrometricQuantities) {
+		if !s[i.name] {
+			continue
+		}
+		sel := u
+		if !i.unit {
+			// no selection emits the base unit only, i.e. the value as is
+			sel = nil
+		}
+		g.gauges = append(g.gauges, sel.NewGauge(i.name, i.help, i.quantity, opts...))
+		g.values = append(g.values, i.value)
+	}
+	return g
+}
+
+// Metrics returns a gauge per selected index and unit, in the order of thermal indices then psychrometric quantities
+func (g *Gauges) Metrics() []metrics.Metric {
+	var ret []metrics.Metric
+	for _, gauge := range g.gauges {
+		ret = append(ret, gauge.Metrics()...)
+	}
+	return ret
+}
+
+// Set sets the indices by temperature in celsius and relative humidity
+func (g *Gauges) Set(labels metrics.Labels, temp, relativeHumid float64) {
+	g.SetWithPressure(labels, temp, relativeHumid, weather.StandardPressure, time.Time{})
+}
+
+// SetWithTimeout sets the indices by temperature in celsius and relative humidity,
+// assuming the standard pressure.
+func (g *Gauges) SetWithTimeout(labels metrics.Labels, temp, relativeHumid float64, expireAt time.Time) {
+	g.SetWithPressure(labels, temp, relativeHumid, weather.StandardPressure, expireAt)
+}
+
+// SetWithPressure sets the indices by temperature in celsius, relative humidity
+// and station pressure in hectopascals.
+func (g *Gauges) SetWithPressure(labels metrics.Labels, temp, relativeHumid, pressure float64, expireAt time.Time) {
+	for i, gauge := range g.gauges {
+		gauge.SetWithTimeout(labels, metrics.RoundFloat64{
+			Value:     g.values[i](temp, relativeHumid, pressure),
+			Precision: 2,
+		}, expireAt)
+	}
+}
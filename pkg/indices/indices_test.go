@@ -0,0 +1,242 @@
+package indices
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/units"
+)
+
+func TestSelectionFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	s := RegisterFlag(fs)
+
+	if err := fs.Parse([]string{"--indices", "humidex, heat_index"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if got, want := s.String(), "heat_index,humidex"; got != want {
+		t.Errorf("selection failed: got:%q want:%q", got, want)
+	}
+
+	if err := fs.Parse([]string{"--indices", "wind_chill"}); err == nil {
+		t.Errorf("Parse() of unknown index should fail")
+	}
+}
+
+func TestGauges(t *testing.T) {
+	g := Selection{HeatIndex: true, Humidex: true, ApparentTemperature: true}.NewGauges(units.DefaultSelection())
+
+	set := metrics.MetricSet{}
+	set.Add(g.Metrics()...)
+	g.Set(metrics.Labels{"place": "inside"}, 32, 60)
+
+	var buf bytes.Buffer
+	if err := set.Write(&buf); err != nil {
+		t.Errorf("Write() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP apparent_temperature Apparent Temperature (Steadman, without wind)
+# TYPE apparent_temperature gauge
+apparent_temperature{place="inside"} 37.38
+# HELP heat_index Heat Index (NOAA)
+# TYPE heat_index gauge
+heat_index{place="inside"} 37.07
+# HELP humidex Humidex (Environment Canada)
+# TYPE humidex gauge
+humidex{place="inside"} 42.53
+`
+	if got != want {
+		t.Errorf("indices failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestPsychrometrics(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	s := RegisterPsychrometricsFlag(fs)
+	if err := fs.Parse([]string{"--psychrometrics", "vapor_pressure,mixing_ratio,wet_bulb"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if err := fs.Parse([]string{"--psychrometrics", "humidex"}); err == nil {
+		t.Errorf("Parse() of thermal index should fail")
+	}
+
+	g := s.NewGauges(units.DefaultSelection())
+
+	set := metrics.MetricSet{}
+	set.Add(g.Metrics()...)
+	g.SetWithPressure(metrics.Labels{"place": "inside"}, 20, 50, 850, time.Time{})
+
+	var buf bytes.Buffer
+	if err := set.Write(&buf); err != nil {
+		t.Errorf("Write() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP mixing_ratio Mixing Ratio g/kg
+# TYPE mixing_ratio gauge
+mixing_ratio{place="inside"} 8.69
+# HELP vapor_pressure Vapor Pressure
+# TYPE vapor_pressure gauge
+vapor_pressure{place="inside"} 11.71
+# HELP wet_bulb Wet-bulb Temperature
+# TYPE wet_bulb gauge
+wet_bulb{place="inside"} 13.41
+`
+	if got != want {
+		t.Errorf("psychrometrics failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestGaugesNone(t *testing.T) {
+	g := Selection{}.NewGauges(units.DefaultSelection())
+	if got := len(g.Metrics()); got != 0 {
+		t.Errorf("no index selected but got %d metrics", got)
+	}
+	// must not panic
+	g.Set(metrics.Labels{"place": "inside"}, 32, 60)
+}
+
+func TestVPD(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	c := RegisterVPDFlags(fs)
+
+	if got := len(c.NewVPD().Metrics()); got != 0 {
+		t.Errorf("VPD disabled but got %d metrics", got)
+	}
+
+	if err := fs.Parse([]string{"--vpd", "--leaf_temp_offset", "-2"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	v := c.NewVPD()
+
+	set := metrics.MetricSet{}
+	set.Add(v.Metrics()...)
+	v.Set(metrics.Labels{"place": "tent"}, 25, 60)
+
+	var buf bytes.Buffer
+	if err := set.Write(&buf); err != nil {
+		t.Errorf("Write() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP vapor_pressure_deficit_kpa Vapor Pressure Deficit kPa
+# TYPE vapor_pressure_deficit_kpa gauge
+vapor_pressure_deficit_kpa{place="tent"} 0.906
+# HELP vpd_stage Growth stage suited for the vapor pressure deficit
+# TYPE vpd_stage gauge
+vpd_stage{place="tent",vpd_stage="too_low"} 0
+vpd_stage{place="tent",vpd_stage="propagation"} 0
+vpd_stage{place="tent",vpd_stage="vegetative"} 1
+vpd_stage{place="tent",vpd_stage="flowering"} 0
+vpd_stage{place="tent",vpd_stage="too_high"} 0
+`
+	if got != want {
+		t.Errorf("VPD failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestTendency(t *testing.T) {
+	tendency := NewTendency(units.DefaultSelection())
+	set := metrics.MetricSet{}
+	set.Add(tendency.Metrics()...)
+
+	labels := metrics.Labels{"place": "inside"}
+	// another series has its own history, not covering 3 hours yet
+	outside := metrics.Labels{"place": "outside"}
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	tendency.Add(labels, start, 1010, time.Time{})
+	tendency.Add(outside, start.Add(time.Hour), 990, time.Time{})
+	tendency.Add(labels, start.Add(90*time.Minute), 1011, time.Time{})
+	tendency.Add(labels, start.Add(3*time.Hour), 1012, time.Time{})
+	tendency.Add(outside, start.Add(3*time.Hour), 995, time.Time{})
+
+	var buf bytes.Buffer
+	if err := set.Write(&buf); err != nil {
+		t.Errorf("Write() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP pressure_characteristic Characteristic of pressure tendency (WMO code table 0200)
+# TYPE pressure_characteristic gauge
+pressure_characteristic{place="inside"} 2
+# HELP pressure_tendency Change of sea level pressure in 3 hours
+# TYPE pressure_tendency gauge
+pressure_tendency{place="inside"} 2.00
+# HELP zambretti_forecast_number Zambretti forecast number from 1 (settled fine) to 32 (stormy)
+# TYPE zambretti_forecast_number gauge
+zambretti_forecast_number{place="inside"} 23
+# HELP zambretti_forecast_info Zambretti forecast
+# TYPE zambretti_forecast_info gauge
+zambretti_forecast_info{forecast="Fairly fine, improving",letter="F",place="inside"} 1
+# HELP pressure_trend Trend of sea level pressure in 3 hours
+# TYPE pressure_trend gauge
+pressure_trend{place="inside",pressure_trend="falling"} 0
+pressure_trend{place="inside",pressure_trend="steady"} 0
+pressure_trend{place="inside",pressure_trend="rising"} 1
+`
+	if got != want {
+		t.Errorf("tendency failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestMold(t *testing.T) {
+	c := &MoldConfig{}
+	if m, err := c.NewMold(); err != nil || len(m.Metrics()) != 0 {
+		t.Errorf("mold disabled but got %v, %v", m, err)
+	}
+
+	c = &MoldConfig{Enabled: true, StatePath: filepath.Join(t.TempDir(), "mold.json")}
+	m, err := c.NewMold()
+	if err != nil {
+		t.Fatalf("NewMold() failed: %v", err)
+	}
+
+	labels := metrics.Labels{"place": "basement"}
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 7 * 24 {
+		m.Update(labels, start.Add(time.Duration(i)*time.Hour), 25, 95, time.Time{})
+	}
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// restored after restart
+	m, err = c.NewMold()
+	if err != nil {
+		t.Fatalf("NewMold() to restore failed: %v", err)
+	}
+	set := metrics.MetricSet{}
+	set.Add(m.Metrics()...)
+	m.Update(labels, start.Add(7*24*time.Hour), 25, 95, time.Time{})
+
+	var buf bytes.Buffer
+	if err := set.Write(&buf); err != nil {
+		t.Errorf("Write() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP mold_index Mold index by VTT model, from 0 (no growth) to 6 (heavy growth)
+# TYPE mold_index gauge
+mold_index{place="basement"} 0.587
+`
+	if got != want {
+		t.Errorf("mold failed: got:%q want:%q", got, want)
+	}
+
+	if err := os.WriteFile(c.StatePath, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.NewMold(); err == nil {
+		t.Errorf("NewMold() of broken state should fail")
+	}
+}
@@ -0,0 +1,160 @@
+package indices
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/util"
+	"github.com/walkure/homeprobe/pkg/weather"
+)
+
+// MoldConfig configures the mold index
+type MoldConfig struct {
+	Enabled bool
+	// StatePath is the file to keep the state over restarts. Not kept if empty.
+	StatePath string
+}
+
+// RegisterMoldFlags registers flags `mold_index` and `mold_state` to fs, and returns the config by them.
+func RegisterMoldFlags(fs *flag.FlagSet) *MoldConfig {
+	c := &MoldConfig{}
+	fs.BoolVar(&c.Enabled, "mold_index", false, "Expose mold index by the VTT mold growth model")
+	fs.StringVar(&c.StatePath, "mold_state", "", "File to keep the state of mold index over restarts")
+	return c
+}
+
+// moldLocation is the state of a series, saved as JSON
+type moldLocation struct {
+	Labels metrics.Labels    `json:"labels"`
+	State  weather.MoldState `json:"state"`
+}
+
+// Mold keeps the mold growth model of each series and sets its index
+type Mold struct {
+	mu        sync.Mutex
+	path      string
+	locations map[string]*moldLocation
+	index     metrics.Metric
+}
+
+// NewMold creates the gauge if enabled, restoring the state from the file if exists
+func (c *MoldConfig) NewMold(opts ...metrics.Option) (*Mold, error) {
+	if !c.Enabled {
+		return &Mold{}, nil
+	}
+	m := &Mold{
+		path:      c.StatePath,
+		locations: make(map[string]*moldLocation),
+		index:     metrics.NewGauge("mold_index", "Mold index by VTT model, from 0 (no growth) to 6 (heavy growth)", opts...),
+	}
+	if m.path == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mold state: %w", err)
+	}
+	var locations []*moldLocation
+	if err := json.Unmarshal(data, &locations); err != nil {
+		return nil, fmt.Errorf("mold state %s: %w", m.path, err)
+	}
+	for _, l := range locations {
+		m.locations[l.Labels.String()] = l
+	}
+	return m, nil
+}
+
+// Metrics returns the mold_index gauge, or none if the mold index is disabled
+func (m *Mold) Metrics() []metrics.Metric {
+	if m.index == nil {
+		return nil
+	}
+	return []metrics.Metric{m.index}
+}
+
+// Set updates the model of the series by temperature in celsius and relative humidity at now
+func (m *Mold) Set(labels metrics.Labels, temp, relativeHumid float64) {
+	m.Update(labels, time.Now(), temp, relativeHumid, time.Time{})
+}
+
+// Update updates the model of the series by temperature in celsius and relative humidity at the time
+func (m *Mold) Update(labels metrics.Labels, at time.Time, temp, relativeHumid float64, expireAt time.Time) {
+	if m.index == nil {
+		return
+	}
+	m.mu.Lock()
+	l, ok := m.locations[labels.String()]
+	if !ok {
+		l = &moldLocation{Labels: labels}
+		m.locations[labels.String()] = l
+	}
+	l.State.Update(at, temp, relativeHumid)
+	index := l.State.Index
+	m.mu.Unlock()
+
+	m.index.SetWithTimeout(labels, metrics.RoundFloat64{
+		Value:     index,
+		Precision: 3,
+	}, expireAt)
+}
+
+// Save writes the state to the file
+func (m *Mold) Save() error {
+	if m.index == nil || m.path == "" {
+		return nil
+	}
+	m.mu.Lock()
+	locations := make([]*moldLocation, 0, len(m.locations))
+	for _, k := range util.Keys(m.locations) {
+		locations = append(locations, m.locations[k])
+	}
+	data, err := json.MarshalIndent(locations, "", "  ")
+	m.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("mold state: %w", err)
+	}
+
+	// replace at once not to leave a broken file
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("mold state: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("mold state: %w", err)
+	}
+	return nil
+}
+
+// StartSaver saves the state in background every interval until ctx is done.
+// onError is called with the error of saving.
+func (m *Mold) StartSaver(ctx context.Context, interval time.Duration, onError func(error)) {
+	if m.index == nil || m.path == "" {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Save(); err != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
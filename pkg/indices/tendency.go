@@ -0,0 +1,79 @@
+package indices
+
+import (
+	"sync"
+	"time"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/units"
+	"github.com/walkure/homeprobe/pkg/weather"
+)
+
+// Tendency keeps the history of sea level pressure for each series, and sets its tendency
+// and the Zambretti forecast once the history covers weather.TendencyPeriod.
+type Tendency struct {
+	mu sync.Mutex
+	// history by labels.String()
+	histories      map[string]*weather.PressureHistory
+	change         *units.Gauge
+	characteristic metrics.Metric
+	trend          metrics.StateSet
+	forecastNumber metrics.Metric
+	forecast       metrics.Info
+}
+
+// NewTendency creates the metrics. The change follows the units of pressure.
+func NewTendency(u units.Selection, opts ...metrics.Option) *Tendency {
+	return &Tendency{
+		histories:      make(map[string]*weather.PressureHistory),
+		change:         u.NewGauge("pressure_tendency", "Change of sea level pressure in 3 hours", units.Pressure, opts...),
+		characteristic: metrics.NewGauge("pressure_characteristic", "Characteristic of pressure tendency (WMO code table 0200)", opts...),
+		trend:          metrics.NewStateSet("pressure_trend", "Trend of sea level pressure in 3 hours", weather.PressureTrendNames, opts...),
+		forecastNumber: metrics.NewGauge("zambretti_forecast_number", "Zambretti forecast number from 1 (settled fine) to 32 (stormy)", opts...),
+		forecast:       metrics.NewInfo("zambretti_forecast", "Zambretti forecast", opts...),
+	}
+}
+
+// Metrics returns the pressure_tendency gauges per unit, the characteristic and the trend
+// of the tendency, and the Zambretti forecast number and info
+func (t *Tendency) Metrics() []metrics.Metric {
+	return append(t.change.Metrics(), t.characteristic, t.trend, t.forecastNumber, t.forecast)
+}
+
+// Add adds the sea level pressure in hectopascals at the time to the history, and sets the tendency
+func (t *Tendency) Add(labels metrics.Labels, at time.Time, seaLevelPressure float64, expireAt time.Time) {
+	history := t.history(labels)
+	history.Add(at, seaLevelPressure)
+	tendency, ok := history.Tendency()
+	if !ok {
+		return
+	}
+
+	t.change.SetWithTimeout(labels, metrics.RoundFloat64{
+		Value:     tendency.Change,
+		Precision: 2,
+	}, expireAt)
+	t.characteristic.SetWithTimeout(labels, metrics.Int64(tendency.Characteristic), expireAt)
+	t.trend.SetStateWithTimeout(labels, tendency.Trend.String(), expireAt)
+
+	forecast := weather.ZambrettiForecast(seaLevelPressure, tendency.Trend)
+	t.forecastNumber.SetWithTimeout(labels, metrics.Int64(forecast.Number), expireAt)
+	t.forecast.SetInfoWithTimeout(labels, metrics.Labels{
+		"letter":   forecast.Letter,
+		"forecast": forecast.Text,
+	}, expireAt)
+}
+
+// history returns the history of the series identified by labels
+func (t *Tendency) history(labels metrics.Labels) *weather.PressureHistory {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := labels.String()
+	h, ok := t.histories[key]
+	if !ok {
+		h = weather.NewPressureHistory()
+		t.histories[key] = h
+	}
+	return h
+}
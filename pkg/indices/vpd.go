@@ -0,0 +1,69 @@
+package indices
+
+import (
+	"flag"
+	"time"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/weather"
+)
+
+// VPDConfig configures the vapor pressure deficit
+type VPDConfig struct {
+	Enabled bool
+	// LeafOffset is the leaf temperature relative to the air. 0 for the VPD of air.
+	LeafOffset float64
+}
+
+// RegisterVPDFlags registers flags `vpd` and `leaf_temp_offset` to fs, and returns the config by them.
+func RegisterVPDFlags(fs *flag.FlagSet) *VPDConfig {
+	c := &VPDConfig{}
+	fs.BoolVar(&c.Enabled, "vpd", false, "Expose vapor pressure deficit and its growth stage")
+	fs.Float64Var(&c.LeafOffset, "leaf_temp_offset", 0, "Leaf temperature relative to the air for vapor pressure deficit")
+	return c
+}
+
+// VPD sets the vapor pressure deficit and its growth stage
+type VPD struct {
+	deficit    metrics.Metric
+	stage      metrics.StateSet
+	leafOffset float64
+}
+
+// NewVPD creates the gauge and the stateset if enabled
+func (c *VPDConfig) NewVPD(opts ...metrics.Option) *VPD {
+	if !c.Enabled {
+		return &VPD{}
+	}
+	return &VPD{
+		deficit:    metrics.NewGauge("vapor_pressure_deficit_kpa", "Vapor Pressure Deficit kPa", opts...),
+		stage:      metrics.NewStateSet("vpd_stage", "Growth stage suited for the vapor pressure deficit", weather.VPDStageNames, opts...),
+		leafOffset: c.LeafOffset,
+	}
+}
+
+// Metrics returns the vapor_pressure_deficit_kpa gauge and the vpd_stage stateset, or none if VPD is disabled
+func (v *VPD) Metrics() []metrics.Metric {
+	if v.deficit == nil {
+		return nil
+	}
+	return []metrics.Metric{v.deficit, v.stage}
+}
+
+// Set sets the deficit by temperature in celsius and relative humidity
+func (v *VPD) Set(labels metrics.Labels, temp, relativeHumid float64) {
+	v.SetWithTimeout(labels, temp, relativeHumid, time.Time{})
+}
+
+// SetWithTimeout sets the deficit by temperature in celsius and relative humidity
+func (v *VPD) SetWithTimeout(labels metrics.Labels, temp, relativeHumid float64, expireAt time.Time) {
+	if v.deficit == nil {
+		return
+	}
+	deficit := weather.LeafVaporPressureDeficit(temp, relativeHumid, v.leafOffset)
+	v.deficit.SetWithTimeout(labels, metrics.RoundFloat64{
+		Value:     deficit,
+		Precision: 3,
+	}, expireAt)
+	v.stage.SetStateWithTimeout(labels, weather.VPDStageOf(deficit).String(), expireAt)
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Counter is a monotonically increasing metric
+type Counter interface {
+	Metric
+	Inc(labels Labels)
+	Add(labels Labels, delta float64)
+	AddWithTimeout(labels Labels, delta float64, expireAt time.Time)
+}
+
+func NewCounter(name, help string, opts ...Option) Counter {
+	name = strings.TrimSuffix(name, "_total")
+	mustValidMetricName(name)
+	c := &counterEntity{
+		metricEntity: metricEntity{
+			metricName: name,
+			help:       help,
+			values:     make(map[string]metricValueItem),
+			metricType: "counter",
+		},
+	}
+	for _, opt := range opts {
+		opt(&c.metricEntity)
+	}
+	return c
+}
+
+type counterEntity struct {
+	metricEntity
+}
+
+func (c *counterEntity) Inc(labels Labels) {
+	c.AddWithTimeout(labels, 1, time.Time{})
+}
+
+func (c *counterEntity) Add(labels Labels, delta float64) {
+	c.AddWithTimeout(labels, delta, time.Time{})
+}
+
+// AddWithTimeout increases the counter by delta. Negative deltas are ignored.
+func (c *counterEntity) AddWithTimeout(labels Labels, delta float64, expireAt time.Time) {
+	if delta < 0 {
+		return
+	}
+	c.update(labels, expireAt, func(v float64) float64 {
+		return v + delta
+	})
+}
+
+// Set overwrites the counter value, e.g. with a counter read from a device.
+func (c *counterEntity) Set(labels Labels, value Value) {
+	c.SetWithTimeout(labels, value, time.Time{})
+}
+
+func (c *counterEntity) SetWithTimeout(labels Labels, value Value, expireAt time.Time) {
+	value = c.round(value)
+	c.update(labels, expireAt, func(float64) float64 {
+		return value.Float64()
+	})
+}
+
+func (c *counterEntity) update(labels Labels, expireAt time.Time, fn func(float64) float64) {
+	labels, ok := c.seriesLabels(labels)
+	if !ok {
+		return
+	}
+	key := labels.String()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.values[key].(metricCounterItem)
+	if !ok {
+		item = metricCounterItem{
+			series:  series{labels: labels},
+			created: timeNow(),
+		}
+	}
+	item.value = fn(item.value)
+	item.expireAt = expireAt
+	item.updatedAt = timeNow()
+	item.emitCreated = c.emitCreated
+	item.emitTimestamp = c.emitTimestamp
+	c.values[key] = item
+}
+
+// metricCounterItem is a counter value with labels
+type metricCounterItem struct {
+	series
+	value         float64
+	created       time.Time
+	emitCreated   bool
+	emitTimestamp bool
+}
+
+func (m metricCounterItem) writeValue(name string, w io.Writer, f Format) error {
+	io.WriteString(w, name+"_total")
+	io.WriteString(w, m.labels.String())
+	io.WriteString(w, " ")
+	io.WriteString(w, formatFloat(m.value))
+	if m.emitTimestamp {
+		writeTimestamp(w, m.updatedAt, f)
+	}
+	io.WriteString(w, "\n")
+
+	if m.emitCreated {
+		writeCreated(w, name, m.labels, m.created, f)
+	}
+
+	return nil
+}
+
+func (m metricCounterItem) protoMetrics(string) []*dto.Metric {
+	c := &dto.Counter{Value: proto.Float64(m.value)}
+	if m.emitCreated {
+		c.CreatedTimestamp = timestamppb.New(m.created)
+	}
+	return []*dto.Metric{protoTimestamp(&dto.Metric{
+		Label:   protoLabels(m.labels),
+		Counter: c,
+	}, m.updatedAt, m.emitTimestamp)}
+}
+
+func (m metricCounterItem) valueToString() string {
+	return formatFloat(m.value)
+}
+
+func (m metricCounterItem) logAttr() slog.Attr {
+	return slog.Group("metric",
+		m.labels.LogAttr(),
+		slog.String("value", formatFloat(m.value)),
+	)
+}
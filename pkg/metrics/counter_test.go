@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCounterMetric(t *testing.T) {
+	v := NewCounter("testValue_total", "testHelp")
+	v.Inc(Labels{"l1": "b"})
+	v.Add(Labels{"l1": "b"}, 2.5)
+	v.Add(Labels{"l1": "b"}, -1)
+	v.Inc(Labels{"l1": "c"})
+
+	var buf bytes.Buffer
+	err := v.outputMetric(&buf, testNow)
+	if err != nil {
+		t.Errorf("counterMetric.outputMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue_total testHelp
+# TYPE testValue_total counter
+testValue_total{l1="b"} 3.5
+testValue_total{l1="c"} 1
+`
+	if got != want {
+		t.Errorf("counterMetric.outputMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestCounterMetricCreated(t *testing.T) {
+	timeNow = func() time.Time { return testBeforeNow }
+	defer func() { timeNow = time.Now }()
+
+	v := NewCounter("testValue", "testHelp", WithCreated())
+	v.Inc(nil)
+	v.Set(nil, RoundFloat64{Value: 10})
+
+	var buf bytes.Buffer
+	err := v.writeMetric(&buf, testNow, FormatOpenMetrics)
+	if err != nil {
+		t.Errorf("counterMetric.writeMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue counter
+testValue_total 10
+testValue_created 971176270.000
+`
+	if got != want {
+		t.Errorf("counterMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+
+	// no _created in the text format
+	buf.Reset()
+	v.writeMetric(&buf, testNow, FormatText)
+	want = `# HELP testValue_total testHelp
+# TYPE testValue_total counter
+testValue_total 10
+`
+	if got := buf.String(); got != want {
+		t.Errorf("counterMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestCounterMetricExpiration(t *testing.T) {
+	v := NewCounter("testValue", "testHelp")
+	v.AddWithTimeout(Labels{"expireAt": "old"}, 1, testBeforeNow)
+	v.AddWithTimeout(Labels{"expireAt": "new"}, 1, testAfterNow)
+	v.AddWithTimeout(Labels{"expireAt": "new"}, 1, testAfterNow)
+
+	var buf bytes.Buffer
+	err := v.outputMetric(&buf, testNow)
+	if err != nil {
+		t.Errorf("counterMetric.outputMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue_total testHelp
+# TYPE testValue_total counter
+testValue_total{expireAt="new"} 2
+`
+	if got != want {
+		t.Errorf("counterMetric.outputMetric() failed: got:%q want:%q", got, want)
+	}
+}
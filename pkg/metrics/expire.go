@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	loggerFactory "github.com/walkure/homeprobe/pkg/logger"
+	"github.com/walkure/homeprobe/pkg/util"
+)
+
+// ExpireFunc is called when a series of the metric is expired
+type ExpireFunc func(metricName string, labels Labels)
+
+// OnExpire registers fn to be called after a series is expired.
+// fn is called without holding the lock of the metric.
+func (m *metricEntity) OnExpire(fn ExpireFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onExpire = append(m.onExpire, fn)
+}
+
+// Delete removes the series identified by labels (before relabeling), and reports whether it existed.
+// Expire hooks are not called.
+func (m *metricEntity) Delete(labels Labels) bool {
+	labels, ok := m.relabel.Apply(labels)
+	if !ok {
+		return false
+	}
+	key := sanitizeLabels(labels).String()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok = m.values[key]
+	delete(m.values, key)
+	return ok
+}
+
+// Reset removes all the series
+func (m *metricEntity) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.values)
+}
+
+// series is the identity and the lifetime of a series, embedded in the value items
+type series struct {
+	labels    Labels
+	expireAt  time.Time // zero if never expires
+	updatedAt time.Time
+}
+
+// expired reports whether the series is expired at now, with its key
+func (s series) expired(now time.Time) (bool, string) {
+	if s.expireAt.IsZero() || now.Before(s.expireAt) {
+		return false, ""
+	}
+	return true, s.labels.String()
+}
+
+func (s series) lastUpdate() (Labels, time.Time) {
+	return s.labels, s.updatedAt
+}
+
+// liveKeys returns the sorted keys of the series not expired at now. guarded by mu
+func (m *metricEntity) liveKeys(now time.Time) []string {
+	var keys []string
+	for _, k := range util.Keys(m.values) {
+		if it, ok := m.values[k].(metricExpirableItem); ok {
+			if expired, _ := it.expired(now); expired {
+				continue
+			}
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// expire removes the expired series, logs them and calls the hooks
+func (m *metricEntity) expire(now time.Time) {
+	logger := loggerFactory.GetLogger("metrics")
+
+	m.mu.Lock()
+	var expired []Labels
+	for _, k := range util.Keys(m.values) {
+		it, ok := m.values[k].(metricExpirableItem)
+		if !ok {
+			continue
+		}
+		if ok, label := it.expired(now); ok {
+			logger.Warn("expired metrics deleted",
+				slog.String("metric", m.metricName),
+				slog.String("label", label),
+				slog.String("value", m.values[label].valueToString()),
+			)
+			if u, ok := it.(metricUpdatedItem); ok {
+				labels, _ := u.lastUpdate()
+				expired = append(expired, labels)
+			}
+			delete(m.values, label)
+		}
+	}
+	hooks := m.onExpire
+	m.mu.Unlock()
+
+	for _, labels := range expired {
+		for _, fn := range hooks {
+			fn(m.metricName, labels)
+		}
+	}
+}
+
+// OnExpire registers fn to all the metrics in the set
+func (s MetricSet) OnExpire(fn ExpireFunc) {
+	for _, k := range util.Keys(s) {
+		s[k].OnExpire(fn)
+	}
+}
+
+// StartReaper starts a goroutine removing expired series every interval,
+// so that memory is reclaimed and hooks are called even without scrapes.
+// The goroutine stops when ctx is done.
+func (s MetricSet) StartReaper(ctx context.Context, interval time.Duration) {
+	go reap(ctx, interval, s.Expire)
+}
+
+// reap calls expire every interval until ctx is done
+func reap(ctx context.Context, interval time.Duration, expire func(now time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expire(timeNow())
+		}
+	}
+}
+
+// Expire removes the series expired at now
+func (s MetricSet) Expire(now time.Time) {
+	for _, k := range util.Keys(s) {
+		s[k].expire(now)
+	}
+}
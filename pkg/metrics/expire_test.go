@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMetricOnExpire(t *testing.T) {
+	v := NewGauge("testValue", "testHelp")
+	v.SetWithTimeout(Labels{"expireAt": "old"}, RoundFloat64{Value: 1, Precision: 0}, testBeforeNow)
+	v.SetWithTimeout(Labels{"expireAt": "new"}, RoundFloat64{Value: 1, Precision: 0}, testAfterNow)
+
+	var expired []string
+	v.OnExpire(func(name string, labels Labels) {
+		expired = append(expired, name+labels.String())
+	})
+
+	// scrapes skip expired series silently, leaving them to the reaper
+	var buf bytes.Buffer
+	if err := v.outputMetric(&buf, testNow); err != nil {
+		t.Errorf("outputMetric() failed: %v", err)
+	}
+	want := `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{expireAt="new"} 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("outputMetric() of expired series failed: got:%q want:%q", got, want)
+	}
+	if len(expired) != 0 {
+		t.Errorf("outputMetric() must not call hooks: got:%q", expired)
+	}
+
+	v.expire(testNow)
+	v.expire(testNow)
+
+	if len(expired) != 1 || expired[0] != `testValue{expireAt="old"}` {
+		t.Errorf("metric.OnExpire() failed: got:%q", expired)
+	}
+}
+
+func TestMetricDeleteAndReset(t *testing.T) {
+	v := NewCounter("testValue", "testHelp")
+	v.Inc(Labels{"l1": "b"})
+	v.Inc(Labels{"l1": "c"})
+
+	if !v.Delete(Labels{"l1": "b"}) {
+		t.Errorf("metric.Delete() must report existing series")
+	}
+	if v.Delete(Labels{"l1": "b"}) {
+		t.Errorf("metric.Delete() must not report deleted series")
+	}
+
+	var buf bytes.Buffer
+	v.outputMetric(&buf, testNow)
+	want := `# HELP testValue_total testHelp
+# TYPE testValue_total counter
+testValue_total{l1="c"} 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("metric.Delete() failed: got:%q want:%q", got, want)
+	}
+
+	v.Reset()
+	buf.Reset()
+	v.outputMetric(&buf, testNow)
+	if got := buf.String(); got != "" {
+		t.Errorf("metric.Reset() failed: got:%q", got)
+	}
+}
+
+func TestMetricSetReaper(t *testing.T) {
+	s := MetricSet{}
+	v := NewGauge("testValue", "testHelp")
+	s.Add(v)
+	v.SetWithTimeout(Labels{"expireAt": "old"}, RoundFloat64{Value: 1, Precision: 0}, time.Now().Add(-time.Second))
+
+	expired := make(chan Labels, 1)
+	s.OnExpire(func(_ string, labels Labels) {
+		expired <- labels
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.StartReaper(ctx, time.Millisecond)
+
+	select {
+	case labels := <-expired:
+		if labels["expireAt"] != "old" {
+			t.Errorf("metricSet.StartReaper() failed: got:%v", labels)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("metricSet.StartReaper() did not expire series")
+	}
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Format is an exposition format of metrics
+type Format int
+
+const (
+	// FormatText is the Prometheus text format 0.0.4
+	FormatText Format = iota
+	// FormatOpenMetrics is the OpenMetrics text format 1.0.0
+	FormatOpenMetrics
+	// FormatProtobuf is the delimited protobuf format of io.prometheus.client.MetricFamily
+	FormatProtobuf
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatOpenMetrics:
+		return "openmetrics"
+	case FormatProtobuf:
+		return "protobuf"
+	default:
+		return "text"
+	}
+}
+
+// ContentType returns the value of Content-Type header for the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatOpenMetrics:
+		return "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	case FormatProtobuf:
+		return "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited"
+	default:
+		return "text/plain; version=0.0.4; charset=utf-8"
+	}
+}
+
+// Negotiate returns the format most preferred by the Accept header.
+// It falls back to FormatText.
+func Negotiate(h http.Header) Format {
+	best, bestQ := FormatText, 0.0
+	for _, accept := range h.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			f, q, ok := parseMediaRange(part)
+			if ok && q > bestQ {
+				best, bestQ = f, q
+			}
+		}
+	}
+	return best
+}
+
+// parseMediaRange parses a media range of the Accept header
+func parseMediaRange(v string) (Format, float64, bool) {
+	mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(v))
+	if err != nil {
+		return FormatText, 0, false
+	}
+
+	q := 1.0
+	if qs, ok := params["q"]; ok {
+		if q, err = strconv.ParseFloat(qs, 64); err != nil {
+			return FormatText, 0, false
+		}
+	}
+
+	switch mediaType {
+	case "application/vnd.google.protobuf":
+		if params["proto"] == "io.prometheus.client.MetricFamily" && params["encoding"] == "delimited" {
+			return FormatProtobuf, q, true
+		}
+	case "application/openmetrics-text":
+		switch params["version"] {
+		case "", "1.0.0", "0.0.1":
+			return FormatOpenMetrics, q, true
+		}
+	case "text/plain":
+		switch params["version"] {
+		case "", "0.0.4":
+			return FormatText, q, true
+		}
+	case "text/*", "*/*":
+		return FormatText, q, true
+	}
+
+	return FormatText, 0, false
+}
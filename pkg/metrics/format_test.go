@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Format
+	}{
+		{"", FormatText},
+		{"*/*", FormatText},
+		{"text/plain", FormatText},
+		{"application/json", FormatText},
+		{"application/openmetrics-text", FormatOpenMetrics},
+		{"application/openmetrics-text; version=2.0.0", FormatText},
+		{"application/openmetrics-text;version=1.0.0;q=0.5,application/openmetrics-text;version=0.0.1;q=0.4,text/plain;version=0.0.4;q=0.3,*/*;q=0.2", FormatOpenMetrics},
+		{"text/plain;version=0.0.4;q=0.9,application/openmetrics-text;version=1.0.0;q=0.5", FormatText},
+	}
+
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.accept != "" {
+			h.Set("Accept", tt.accept)
+		}
+		if got := Negotiate(h); got != tt.want {
+			t.Errorf("Negotiate(%q) failed: got:%v want:%v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestMetricSetOpenMetrics(t *testing.T) {
+	s := MetricSet{}
+	v1 := NewGauge("testValue1_celsius", "testHelp1", WithUnit("celsius"))
+	v2 := NewCounter("testValue2", "testHelp2")
+	s.Add(v1, v2)
+
+	v1.Set(Labels{"l1": "b"}, RoundFloat64{Value: 1134.43543, Precision: 2})
+	v2.Inc(Labels{"l1": "b"})
+
+	var buf bytes.Buffer
+	err := s.WriteFormat(&buf, FormatOpenMetrics)
+	if err != nil {
+		t.Errorf("metricSet.WriteFormat() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue2 testHelp2
+# TYPE testValue2 counter
+testValue2_total{l1="b"} 1
+# HELP testValue1_celsius testHelp1
+# TYPE testValue1_celsius gauge
+# UNIT testValue1_celsius celsius
+testValue1_celsius{l1="b"} 1134.44
+# EOF
+`
+	if got != want {
+		t.Errorf("metricSet.WriteFormat() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestMetricSetServeHTTP(t *testing.T) {
+	s := MetricSet{}
+	v := NewGauge("testValue", "testHelp")
+	s.Add(v)
+	v.Set(nil, RoundFloat64{Value: 1, Precision: 0})
+
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	r.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if got, want := w.Header().Get("Content-Type"), FormatOpenMetrics.ContentType(); got != want {
+		t.Errorf("metricSet.ServeHTTP() Content-Type failed: got:%q want:%q", got, want)
+	}
+	want := "# HELP testValue testHelp\n# TYPE testValue gauge\ntestValue 1\n# EOF\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("metricSet.ServeHTTP() failed: got:%q want:%q", got, want)
+	}
+}
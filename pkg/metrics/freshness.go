@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// lastUpdateSuffix is the suffix of the companion gauge enabled by WithLastUpdate
+const lastUpdateSuffix = "_last_update_timestamp_seconds"
+
+// WithLastUpdate makes the metric emit a companion gauge
+// `<name>_last_update_timestamp_seconds` holding the time of the last update
+// of each series, so that stale sensors are detectable before the series expire.
+func WithLastUpdate() Option {
+	return func(m *metricEntity) {
+		m.emitLastUpdate = true
+	}
+}
+
+// metricUpdatedItem is an item which knows when it was updated
+type metricUpdatedItem interface {
+	lastUpdate() (Labels, time.Time)
+}
+
+// writeLastUpdate writes the companion gauge of last update time of keys. guarded by mu
+func (m *metricEntity) writeLastUpdate(w io.Writer, metricName string, keys []string, f Format) error {
+	name := metricName + lastUpdateSuffix
+	help := "Last update time of " + metricName
+
+	if f == FormatProtobuf {
+		var metrics []*dto.Metric
+		for _, k := range keys {
+			if it, ok := m.values[k].(metricUpdatedItem); ok {
+				labels, at := it.lastUpdate()
+				metrics = append(metrics, protoGauge(labels, float64(at.UnixMilli())/1000))
+			}
+		}
+		return writeProtoFamily(w, name, help, dto.MetricType_GAUGE, "seconds", metrics)
+	}
+
+	io.WriteString(w, fmt.Sprintf("# HELP %s %s\n", name, escapeHelp(help, f)))
+	io.WriteString(w, fmt.Sprintf("# TYPE %s gauge\n", name))
+	if f == FormatOpenMetrics {
+		io.WriteString(w, fmt.Sprintf("# UNIT %s seconds\n", name))
+	}
+	for _, k := range keys {
+		if it, ok := m.values[k].(metricUpdatedItem); ok {
+			labels, at := it.lastUpdate()
+			writeSample(w, name, labels, formatTimestamp(at))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLastUpdateMetric(t *testing.T) {
+	timeNow = func() time.Time { return testBeforeNow }
+	defer func() { timeNow = time.Now }()
+
+	v := NewGauge("testValue", "testHelp", WithLastUpdate())
+	v.SetWithTimeout(Labels{"expireAt": "old"}, RoundFloat64{Value: 1, Precision: 0}, testBeforeNow)
+	v.SetWithTimeout(Labels{"expireAt": "new"}, RoundFloat64{Value: 2, Precision: 0}, testAfterNow)
+
+	var buf bytes.Buffer
+	err := v.writeMetric(&buf, testNow, FormatText)
+	if err != nil {
+		t.Errorf("gaugeMetric.writeMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{expireAt="new"} 2
+# HELP testValue_last_update_timestamp_seconds Last update time of testValue
+# TYPE testValue_last_update_timestamp_seconds gauge
+testValue_last_update_timestamp_seconds{expireAt="new"} 971176270.000
+`
+	if got != want {
+		t.Errorf("gaugeMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+
+	buf.Reset()
+	err = v.writeMetric(&buf, testNow, FormatOpenMetrics)
+	if err != nil {
+		t.Errorf("gaugeMetric.writeMetric() failed: %v", err)
+	}
+
+	got = buf.String()
+	want = `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{expireAt="new"} 2
+# HELP testValue_last_update_timestamp_seconds Last update time of testValue
+# TYPE testValue_last_update_timestamp_seconds gauge
+# UNIT testValue_last_update_timestamp_seconds seconds
+testValue_last_update_timestamp_seconds{expireAt="new"} 971176270.000
+`
+	if got != want {
+		t.Errorf("gaugeMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestLastUpdateMetricConflict(t *testing.T) {
+	s := MetricSet{}
+	s.Add(NewCounter("testValue", "testHelp", WithLastUpdate()))
+
+	if err := s.Register(NewGauge("testValue_last_update_timestamp_seconds", "testHelp")); err == nil {
+		t.Errorf("metricSet.Register() must fail")
+	}
+}
@@ -0,0 +1,250 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Histogram counts observations in configurable buckets
+type Histogram interface {
+	Metric
+	Observe(labels Labels, value float64)
+	ObserveWithTimeout(labels Labels, value float64, expireAt time.Time)
+}
+
+// DefBuckets are default buckets suitable for durations in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// LinearBuckets returns count buckets, each width wide, the lowest is start.
+func LinearBuckets(start, width float64, count int) []float64 {
+	if count < 1 {
+		panic("LinearBuckets needs a positive count")
+	}
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start += width
+	}
+	return buckets
+}
+
+// ExponentialBuckets returns count buckets, the lowest is start and each is factor times the previous.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	if count < 1 {
+		panic("ExponentialBuckets needs a positive count")
+	}
+	if start <= 0 {
+		panic("ExponentialBuckets needs a positive start value")
+	}
+	if factor <= 1 {
+		panic("ExponentialBuckets needs a factor greater than 1")
+	}
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// NewHistogram creates a histogram. nil buckets means DefBuckets.
+// The +Inf bucket is added implicitly.
+func NewHistogram(name, help string, buckets []float64, opts ...Option) Histogram {
+	mustValidMetricName(name)
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	buckets = slices.Clone(buckets)
+	if len(buckets) > 0 && math.IsInf(buckets[len(buckets)-1], 1) {
+		buckets = buckets[:len(buckets)-1]
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i-1] >= buckets[i] {
+			panic(fmt.Sprintf("histogram %s buckets not sorted: %v", name, buckets))
+		}
+	}
+
+	h := &histogramEntity{
+		metricEntity: metricEntity{
+			metricName: name,
+			help:       help,
+			values:     make(map[string]metricValueItem),
+			metricType: "histogram",
+		},
+		buckets: buckets,
+	}
+	for _, opt := range opts {
+		opt(&h.metricEntity)
+	}
+	h.mustValidRelabel("le")
+	return h
+}
+
+type histogramEntity struct {
+	metricEntity
+	buckets []float64
+}
+
+func (h *histogramEntity) Observe(labels Labels, value float64) {
+	h.ObserveWithTimeout(labels, value, time.Time{})
+}
+
+func (h *histogramEntity) ObserveWithTimeout(labels Labels, value float64, expireAt time.Time) {
+	labels, ok := h.seriesLabels(labels, "le")
+	if !ok {
+		return
+	}
+	key := labels.String()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	item, ok := h.values[key].(*metricHistogramItem)
+	if !ok {
+		item = &metricHistogramItem{
+			series:  series{labels: labels},
+			buckets: h.buckets,
+			counts:  make([]uint64, len(h.buckets)),
+			created: timeNow(),
+		}
+		h.values[key] = item
+	}
+	item.observe(value)
+	item.expireAt = expireAt
+	item.updatedAt = timeNow()
+	item.emitCreated = h.emitCreated
+	item.emitTimestamp = h.emitTimestamp
+}
+
+// Set records value as an observation.
+func (h *histogramEntity) Set(labels Labels, value Value) {
+	h.ObserveWithTimeout(labels, value.Float64(), time.Time{})
+}
+
+// SetWithTimeout records value as an observation.
+func (h *histogramEntity) SetWithTimeout(labels Labels, value Value, expireAt time.Time) {
+	h.ObserveWithTimeout(labels, value.Float64(), expireAt)
+}
+
+// metricHistogramItem is a histogram with labels. guarded by metricEntity.mu
+type metricHistogramItem struct {
+	series
+	buckets       []float64
+	counts        []uint64 // not cumulative
+	count         uint64
+	sum           float64
+	created       time.Time
+	emitCreated   bool
+	emitTimestamp bool
+}
+
+func (m *metricHistogramItem) observe(v float64) {
+	// the first bucket whose upper bound is >= v. NaN goes to +Inf only.
+	if i, _ := slices.BinarySearch(m.buckets, v); i < len(m.buckets) && !math.IsNaN(v) {
+		m.counts[i]++
+	}
+	m.count++
+	m.sum += v
+}
+
+func (m *metricHistogramItem) writeValue(name string, w io.Writer, f Format) error {
+	var cumulative uint64
+	for i, le := range m.buckets {
+		cumulative += m.counts[i]
+		writeSampleAt(w, name+"_bucket", m.labels.with("le", formatFloat(le)), formatFloat(float64(cumulative)), m.timestamp(), f)
+	}
+	writeSampleAt(w, name+"_bucket", m.labels.with("le", "+Inf"), formatFloat(float64(m.count)), m.timestamp(), f)
+	writeSampleAt(w, name+"_sum", m.labels, formatFloat(m.sum), m.timestamp(), f)
+	writeSampleAt(w, name+"_count", m.labels, formatFloat(float64(m.count)), m.timestamp(), f)
+	if m.emitCreated {
+		writeCreated(w, name, m.labels, m.created, f)
+	}
+
+	return nil
+}
+
+func (m *metricHistogramItem) protoMetrics(string) []*dto.Metric {
+	h := &dto.Histogram{
+		SampleCount: proto.Uint64(m.count),
+		SampleSum:   proto.Float64(m.sum),
+	}
+	// +Inf bucket is implied by sample_count
+	var cumulative uint64
+	for i, le := range m.buckets {
+		cumulative += m.counts[i]
+		h.Bucket = append(h.Bucket, &dto.Bucket{
+			CumulativeCount: proto.Uint64(cumulative),
+			UpperBound:      proto.Float64(le),
+		})
+	}
+	if m.emitCreated {
+		h.CreatedTimestamp = timestamppb.New(m.created)
+	}
+	return []*dto.Metric{protoTimestamp(&dto.Metric{
+		Label:     protoLabels(m.labels),
+		Histogram: h,
+	}, m.updatedAt, m.emitTimestamp)}
+}
+
+// timestamp returns the time of the samples, or zero if not emitted
+func (m *metricHistogramItem) timestamp() time.Time {
+	if !m.emitTimestamp {
+		return time.Time{}
+	}
+	return m.updatedAt
+}
+
+func (m *metricHistogramItem) valueToString() string {
+	return fmt.Sprintf("count=%d sum=%s", m.count, formatFloat(m.sum))
+}
+
+func (m *metricHistogramItem) logAttr() slog.Attr {
+	return slog.Group("metric",
+		m.labels.LogAttr(),
+		slog.Uint64("count", m.count),
+		slog.String("sum", formatFloat(m.sum)),
+	)
+}
+
+// writeSample writes a single sample line
+func writeSample(w io.Writer, name string, labels Labels, value string) {
+	writeSampleAt(w, name, labels, value, time.Time{}, FormatText)
+}
+
+// writeSampleAt writes a single sample line with the timestamp at, unless at is zero
+func writeSampleAt(w io.Writer, name string, labels Labels, value string, at time.Time, f Format) {
+	io.WriteString(w, name)
+	io.WriteString(w, labels.String())
+	io.WriteString(w, " ")
+	io.WriteString(w, value)
+	if !at.IsZero() {
+		writeTimestamp(w, at, f)
+	}
+	io.WriteString(w, "\n")
+}
+
+// writeCreated writes the `_created` sample of the family named name. Only OpenMetrics has it.
+func writeCreated(w io.Writer, name string, labels Labels, created time.Time, f Format) {
+	if f == FormatOpenMetrics {
+		writeSample(w, name+"_created", labels, formatTimestamp(created))
+	}
+}
+
+// with returns a copy of labels with an additional label
+func (l Labels) with(name, value string) Labels {
+	ret := maps.Clone(l)
+	if ret == nil {
+		ret = make(Labels, 1)
+	}
+	ret[name] = value
+	return ret
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHistogramMetric(t *testing.T) {
+	v := NewHistogram("testValue", "testHelp", []float64{0.1, 1, 10})
+	for _, it := range []float64{0.05, 0.1, 0.5, 5, 50, math.NaN()} {
+		v.Observe(Labels{"l1": "b"}, it)
+	}
+	v.Set(Labels{"l1": "c"}, RoundFloat64{Value: 2})
+
+	var buf bytes.Buffer
+	err := v.outputMetric(&buf, testNow)
+	if err != nil {
+		t.Errorf("histogramMetric.outputMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue histogram
+testValue_bucket{l1="b",le="0.1"} 2
+testValue_bucket{l1="b",le="1"} 3
+testValue_bucket{l1="b",le="10"} 4
+testValue_bucket{l1="b",le="+Inf"} 6
+testValue_sum{l1="b"} NaN
+testValue_count{l1="b"} 6
+testValue_bucket{l1="c",le="0.1"} 0
+testValue_bucket{l1="c",le="1"} 0
+testValue_bucket{l1="c",le="10"} 1
+testValue_bucket{l1="c",le="+Inf"} 1
+testValue_sum{l1="c"} 2
+testValue_count{l1="c"} 1
+`
+	if got != want {
+		t.Errorf("histogramMetric.outputMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestHistogramMetricExpiration(t *testing.T) {
+	v := NewHistogram("testValue", "testHelp", []float64{1})
+	v.ObserveWithTimeout(Labels{"expireAt": "old"}, 0.5, testBeforeNow)
+	v.ObserveWithTimeout(Labels{"expireAt": "new"}, 0.5, testAfterNow)
+
+	var buf bytes.Buffer
+	err := v.outputMetric(&buf, testNow)
+	if err != nil {
+		t.Errorf("histogramMetric.outputMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue histogram
+testValue_bucket{expireAt="new",le="1"} 1
+testValue_bucket{expireAt="new",le="+Inf"} 1
+testValue_sum{expireAt="new"} 0.5
+testValue_count{expireAt="new"} 1
+`
+	if got != want {
+		t.Errorf("histogramMetric.outputMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	if got, want := LinearBuckets(1, 2, 3), []float64{1, 3, 5}; !equalFloats(got, want) {
+		t.Errorf("LinearBuckets() failed: got:%v want:%v", got, want)
+	}
+	if got, want := ExponentialBuckets(1, 10, 3), []float64{1, 10, 100}; !equalFloats(got, want) {
+		t.Errorf("ExponentialBuckets() failed: got:%v want:%v", got, want)
+	}
+}
+
+func TestHistogramConcurrentObserve(t *testing.T) {
+	v := NewHistogram("testValue", "testHelp", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v.Observe(Labels{"l1": "b"}, 0.01)
+				var buf bytes.Buffer
+				v.outputMetric(&buf, testNow)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var buf bytes.Buffer
+	v.outputMetric(&buf, testNow)
+	if !bytes.Contains(buf.Bytes(), []byte("testValue_count{l1=\"b\"} 800\n")) {
+		t.Errorf("histogramMetric concurrent Observe failed: got:%q", buf.String())
+	}
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHistogramMetricCreated(t *testing.T) {
+	timeNow = func() time.Time { return testBeforeNow }
+	defer func() { timeNow = time.Now }()
+
+	v := NewHistogram("testValue", "testHelp", []float64{1}, WithCreated(), WithTimestamp())
+	v.Observe(nil, 0.5)
+
+	var buf bytes.Buffer
+	if err := v.writeMetric(&buf, testNow, FormatOpenMetrics); err != nil {
+		t.Errorf("histogramMetric.writeMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue histogram
+testValue_bucket{le="1"} 1 971176270.000
+testValue_bucket{le="+Inf"} 1 971176270.000
+testValue_sum 0.5 971176270.000
+testValue_count 1 971176270.000
+testValue_created 971176270.000
+`
+	if got != want {
+		t.Errorf("histogramMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+
+	// no _created in the text format, timestamps in milliseconds
+	buf.Reset()
+	v.writeMetric(&buf, testNow, FormatText)
+	want = `# HELP testValue testHelp
+# TYPE testValue histogram
+testValue_bucket{le="1"} 1 971176270000
+testValue_bucket{le="+Inf"} 1 971176270000
+testValue_sum 0.5 971176270000
+testValue_count 1 971176270000
+`
+	if got := buf.String(); got != want {
+		t.Errorf("histogramMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"io"
+	"log/slog"
+	"maps"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Info exposes textual information as labels of a series whose value is always 1
+type Info interface {
+	Metric
+	SetInfo(labels Labels, info Labels)
+	SetInfoWithTimeout(labels Labels, info Labels, expireAt time.Time)
+}
+
+// NewInfo creates an info metric. It is exposed as a gauge named `<name>_info`
+// in the formats other than OpenMetrics.
+func NewInfo(name, help string, opts ...Option) Info {
+	name = strings.TrimSuffix(name, "_info")
+	mustValidMetricName(name)
+	i := &infoEntity{
+		metricEntity: metricEntity{
+			metricName: name,
+			help:       help,
+			values:     make(map[string]metricValueItem),
+			metricType: "info",
+		},
+	}
+	for _, opt := range opts {
+		opt(&i.metricEntity)
+	}
+	return i
+}
+
+type infoEntity struct {
+	metricEntity
+}
+
+// SetInfo replaces the information of the series identified by labels.
+func (i *infoEntity) SetInfo(labels Labels, info Labels) {
+	i.SetInfoWithTimeout(labels, info, time.Time{})
+}
+
+func (i *infoEntity) SetInfoWithTimeout(labels Labels, info Labels, expireAt time.Time) {
+	labels, ok := i.seriesLabels(labels)
+	if !ok {
+		return
+	}
+	if err := validateLabels(info); err != nil {
+		i.dropSeries(labels, err)
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.values[labels.String()] = metricInfoItem{
+		series: series{labels: labels, expireAt: expireAt, updatedAt: timeNow()},
+		info:   maps.Clone(sanitizeLabels(info)),
+	}
+}
+
+// Set sets the series without information. value is ignored.
+func (i *infoEntity) Set(labels Labels, _ Value) {
+	i.SetInfoWithTimeout(labels, nil, time.Time{})
+}
+
+// SetWithTimeout sets the series without information. value is ignored.
+func (i *infoEntity) SetWithTimeout(labels Labels, _ Value, expireAt time.Time) {
+	i.SetInfoWithTimeout(labels, nil, expireAt)
+}
+
+// metricInfoItem is an info value with labels
+type metricInfoItem struct {
+	series
+	info Labels
+}
+
+// allLabels returns identifying labels merged with information labels.
+func (m metricInfoItem) allLabels() Labels {
+	return m.labels.merge(m.info)
+}
+
+func (m metricInfoItem) writeValue(name string, w io.Writer, f Format) error {
+	writeSample(w, name+"_info", m.allLabels(), "1")
+	return nil
+}
+
+func (m metricInfoItem) protoMetrics(string) []*dto.Metric {
+	return []*dto.Metric{protoGauge(m.allLabels(), 1)}
+}
+
+func (m metricInfoItem) valueToString() string {
+	return m.info.String()
+}
+
+func (m metricInfoItem) logAttr() slog.Attr {
+	return slog.Group("metric",
+		m.labels.LogAttr(),
+		slog.String("value", m.info.String()),
+	)
+}
+
+// merge returns a copy of labels overwritten by other
+func (l Labels) merge(other Labels) Labels {
+	ret := maps.Clone(l)
+	if ret == nil {
+		ret = make(Labels, len(other))
+	}
+	maps.Copy(ret, other)
+	return ret
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInfoMetric(t *testing.T) {
+	v := NewInfo("testValue_info", "testHelp")
+	v.SetInfo(Labels{"l1": "b"}, Labels{"version": "1.0"})
+	v.SetInfo(Labels{"l1": "b"}, Labels{"version": "2.0"})
+	v.SetInfo(Labels{"l1": "c"}, Labels{"version": "1.0"})
+
+	var buf bytes.Buffer
+	err := v.writeMetric(&buf, testNow, FormatOpenMetrics)
+	if err != nil {
+		t.Errorf("infoMetric.writeMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue info
+testValue_info{l1="b",version="2.0"} 1
+testValue_info{l1="c",version="1.0"} 1
+`
+	if got != want {
+		t.Errorf("infoMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+
+	buf.Reset()
+	err = v.writeMetric(&buf, testNow, FormatText)
+	if err != nil {
+		t.Errorf("infoMetric.writeMetric() failed: %v", err)
+	}
+
+	got = buf.String()
+	want = `# HELP testValue_info testHelp
+# TYPE testValue_info gauge
+testValue_info{l1="b",version="2.0"} 1
+testValue_info{l1="c",version="1.0"} 1
+`
+	if got != want {
+		t.Errorf("infoMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestInfoMetricExpiration(t *testing.T) {
+	v := NewInfo("testValue", "testHelp")
+	v.SetInfoWithTimeout(Labels{"expireAt": "old"}, Labels{"version": "1.0"}, testBeforeNow)
+	v.SetInfoWithTimeout(Labels{"expireAt": "new"}, Labels{"version": "1.0"}, testAfterNow)
+
+	var buf bytes.Buffer
+	err := v.outputMetric(&buf, testNow)
+	if err != nil {
+		t.Errorf("infoMetric.outputMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue_info testHelp
+# TYPE testValue_info gauge
+testValue_info{expireAt="new",version="1.0"} 1
+`
+	if got != want {
+		t.Errorf("infoMetric.outputMetric() failed: got:%q want:%q", got, want)
+	}
+}
@@ -4,33 +4,88 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"strconv"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
+
 	loggerFactory "github.com/walkure/homeprobe/pkg/logger"
 	"github.com/walkure/homeprobe/pkg/util"
 )
 
 type MetricSet map[string]Metric
 
+// Add adds metrics to the set. It panics if a metric conflicts with others.
 func (s MetricSet) Add(m ...Metric) {
+	if err := s.Register(m...); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds metrics to the set. It returns an error if a metric exposes
+// the same name as others, and the set is left unchanged in that case.
+// Adding the same metric again is not an error.
+func (s MetricSet) Register(m ...Metric) error {
+	names := make(map[string]Metric)
+	for _, it := range s {
+		for _, name := range it.sampleNames() {
+			names[name] = it
+		}
+	}
+
+	for _, it := range m {
+		if s[it.entityName()] == it {
+			continue
+		}
+		for _, name := range it.sampleNames() {
+			if other, ok := names[name]; ok && other != it {
+				return fmt.Errorf("metric %s conflicts with %s: %s", it.entityName(), other.entityName(), name)
+			}
+			names[name] = it
+		}
+	}
+
 	for _, it := range m {
 		s[it.entityName()] = it
 	}
+	return nil
 }
 
+// Write writes metrics in the Prometheus text format
 func (s MetricSet) Write(w io.Writer) error {
-	now := time.Now()
+	return s.WriteFormat(w, FormatText)
+}
+
+// WriteFormat writes metrics in the specified exposition format
+func (s MetricSet) WriteFormat(w io.Writer, f Format) error {
+	now := timeNow()
 	for _, k := range util.Keys(s) {
-		if err := s[k].outputMetric(w, now); err != nil {
+		if err := s[k].writeMetric(w, now, f); err != nil {
+			return err
+		}
+	}
+	if f == FormatOpenMetrics {
+		if _, err := io.WriteString(w, "# EOF\n"); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// ServeHTTP writes metrics in the format negotiated by the Accept header
+func (s MetricSet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f := Negotiate(r.Header)
+	w.Header().Set("Content-Type", f.ContentType())
+	if err := s.WriteFormat(w, f); err != nil {
+		loggerFactory.GetLogger("metrics").Error("write metrics",
+			slog.String("format", f.String()),
+			slog.Any("err", err),
+		)
+	}
+}
+
 // satisfy slog.LogValuer interface
 func (s MetricSet) LogValue() slog.Value {
 
@@ -42,20 +97,58 @@ func (s MetricSet) LogValue() slog.Value {
 	return slog.GroupValue(v...)
 }
 
-func NewGauge(name, help string) Metric {
-	return &metricEntity{
+// Option configures a metric at construction time
+type Option func(*metricEntity)
+
+// WithCreated makes the counter, histogram or summary emit `_created` samples in OpenMetrics,
+// and the created timestamp in protobuf
+func WithCreated() Option {
+	return func(m *metricEntity) {
+		m.emitCreated = true
+	}
+}
+
+// WithTimestamp makes the metric emit the time of Set as the sample timestamp,
+// so that event-driven values are not stamped with the scrape time.
+func WithTimestamp() Option {
+	return func(m *metricEntity) {
+		m.emitTimestamp = true
+	}
+}
+
+// WithUnit sets the unit of the metric. It is exposed in OpenMetrics
+// only if the metric name has the unit as its suffix.
+func WithUnit(unit string) Option {
+	return func(m *metricEntity) {
+		m.unit = unit
+	}
+}
+
+func NewGauge(name, help string, opts ...Option) Metric {
+	mustValidMetricName(name)
+	m := &metricEntity{
 		metricName: name,
 		help:       help,
 		values:     make(map[string]metricValueItem),
 		metricType: "gauge",
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 type Metric interface {
 	entityName() string
+	sampleNames() []string
 	outputMetric(w io.Writer, now time.Time) error
-	Set(labels Labels, value RoundFloat64)
-	SetWithTimeout(labels Labels, value RoundFloat64, expireAt time.Time)
+	expire(now time.Time)
+	writeMetric(w io.Writer, now time.Time, f Format) error
+	Set(labels Labels, value Value)
+	SetWithTimeout(labels Labels, value Value, expireAt time.Time)
+	Delete(labels Labels) bool
+	Reset()
+	OnExpire(fn ExpireFunc)
 	LogAttr() slog.Attr
 }
 
@@ -63,68 +156,185 @@ type metricEntity struct {
 	metricType string
 	metricName string
 	help       string
+	unit       string
 	values     map[string]metricValueItem
 	mu         sync.Mutex
+	onExpire   []ExpireFunc
+
+	// emitCreated enables `_created` samples (counter only)
+	emitCreated bool
+	// emitTimestamp enables sample timestamps (gauge and counter)
+	emitTimestamp bool
+	// emitLastUpdate enables the companion gauge of last update time
+	emitLastUpdate bool
+	// relabel rewrites labels of series on Set
+	relabel *Relabeler
+	// collect refreshes values before written, if not nil
+	collect func()
+	// precisions override rounding of float values by the names of the metric
+	precisions Precisions
+	// aliases are other names the metric is also emitted as
+	aliases []string
 }
 
+// timeNow is replaceable for testing
+var timeNow = time.Now
+
 var noneLabels = make(Labels)
 
 func (m *metricEntity) entityName() string {
 	return m.metricType + "_" + m.metricName
 }
 
-func (m *metricEntity) Set(labels Labels, value RoundFloat64) {
+// sampleNames returns all the names this metric exposes
+func (m *metricEntity) sampleNames() []string {
+	var names []string
+	for _, name := range m.names() {
+		names = append(names, m.familySampleNames(name)...)
+	}
+	return names
+}
+
+// familySampleNames returns the names a family of the metric named name exposes
+func (m *metricEntity) familySampleNames(name string) []string {
+	var names []string
+	switch m.metricType {
+	case "counter":
+		names = []string{name, name + "_total", name + "_created"}
+	case "histogram":
+		names = []string{name, name + "_bucket", name + "_sum", name + "_count", name + "_created"}
+	case "summary":
+		names = []string{name, name + "_sum", name + "_count", name + "_created"}
+	case "info":
+		names = []string{name, name + "_info"}
+	default:
+		names = []string{name}
+	}
+	if m.emitLastUpdate {
+		names = append(names, name+lastUpdateSuffix)
+	}
+	return names
+}
+
+func (m *metricEntity) Set(labels Labels, value Value) {
 	m.SetWithTimeout(labels, value, time.Time{})
 }
 
-func (m *metricEntity) SetWithTimeout(labels Labels, value RoundFloat64, expireAt time.Time) {
-	if labels == nil {
-		labels = noneLabels
+func (m *metricEntity) SetWithTimeout(labels Labels, value Value, expireAt time.Time) {
+	labels, ok := m.seriesLabels(labels)
+	if !ok {
+		return
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.values[labels.String()] = metricStringerItem{
-		labels:   labels,
-		value:    value,
-		expireAt: expireAt,
+		series:        series{labels: labels, expireAt: expireAt, updatedAt: timeNow()},
+		value:         m.round(value),
+		emitTimestamp: m.emitTimestamp,
 	}
 }
 
 func (m *metricEntity) outputMetric(w io.Writer, now time.Time) error {
+	return m.writeMetric(w, now, FormatText)
+}
+
+// writeMetric writes the series not expired at now. Expired series are skipped silently,
+// and left to Expire or the reaper to be deleted, logged and passed to the hooks.
+func (m *metricEntity) writeMetric(w io.Writer, now time.Time, f Format) error {
+	if m.collect != nil {
+		m.collect()
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	logger := loggerFactory.GetLogger("metrics")
-
-	// check timeout
-	for _, k := range m.values {
-		if it, ok := k.(metricExpirableItem); ok {
-			if ok, label := it.expired(now); ok {
-				logger.Warn("expired metrics deleted",
-					slog.String("metric", m.metricName),
-					slog.String("label", label),
-					slog.String("value", m.values[label].valueToString()),
-				)
-				delete(m.values, label)
-			}
+	keys := m.liveKeys(now)
+	if len(keys) == 0 {
+		// No values, no output
+		return nil
+	}
+
+	for _, name := range m.names() {
+		if err := m.writeFamily(w, name, keys, f); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	if len(m.values) == 0 {
-		// No values, no output
+// writeFamily writes the values of keys as a family of the metric named name. guarded by mu
+func (m *metricEntity) writeFamily(w io.Writer, name string, keys []string, f Format) error {
+	family := m.familyName(name, f)
+	if f == FormatProtobuf {
+		if err := m.writeProtobuf(w, name, family, keys); err != nil {
+			return err
+		}
+		if m.emitLastUpdate {
+			return m.writeLastUpdate(w, name, keys, f)
+		}
 		return nil
 	}
 
-	io.WriteString(w, fmt.Sprintf("# HELP %s %s\n", m.metricName, m.help))
-	io.WriteString(w, fmt.Sprintf("# TYPE %s %s\n", m.metricName, m.metricType))
-	for _, k := range util.Keys(m.values) {
-		m.values[k].writeValue(m.metricName, w)
+	io.WriteString(w, fmt.Sprintf("# HELP %s %s\n", family, escapeHelp(m.help, f)))
+	io.WriteString(w, fmt.Sprintf("# TYPE %s %s\n", family, m.familyType(f)))
+	if f == FormatOpenMetrics && m.unit != "" && strings.HasSuffix(family, "_"+m.unit) {
+		io.WriteString(w, fmt.Sprintf("# UNIT %s %s\n", family, m.unit))
+	}
+	for _, k := range keys {
+		if err := m.values[k].writeValue(name, w, f); err != nil {
+			return err
+		}
+	}
+
+	if m.emitLastUpdate {
+		return m.writeLastUpdate(w, name, keys, f)
 	}
 
 	return nil
 }
 
+// writeProtobuf writes the values of keys as a length-delimited MetricFamily. guarded by mu
+func (m *metricEntity) writeProtobuf(w io.Writer, name, family string, keys []string) error {
+	var metrics []*dto.Metric
+	for _, k := range keys {
+		metrics = append(metrics, m.values[k].protoMetrics(name)...)
+	}
+	return writeProtoFamily(w, family, m.help, protoMetricType(m.metricType), m.unit, metrics)
+}
+
+// familyName returns the name used in HELP/TYPE lines.
+// Only OpenMetrics has the notion of metric families,
+// so a counter or an info is named after its samples otherwise.
+func (m *metricEntity) familyName(name string, f Format) string {
+	if f == FormatOpenMetrics {
+		return name
+	}
+	switch m.metricType {
+	case "counter":
+		return name + "_total"
+	case "info":
+		return name + "_info"
+	}
+	return name
+}
+
+// familyType returns the type used in TYPE lines.
+// The types only OpenMetrics has are exposed as gauge otherwise.
+func (m *metricEntity) familyType(f Format) string {
+	if f == FormatOpenMetrics {
+		return m.metricType
+	}
+	switch m.metricType {
+	case "info", "stateset":
+		return "gauge"
+	}
+	return m.metricType
+}
+
 func (m *metricEntity) LogAttr() slog.Attr {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	v := []slog.Attr{}
 	for _, k := range util.Keys(m.values) {
 		v = append(v, m.values[k].logAttr())
@@ -133,7 +343,10 @@ func (m *metricEntity) LogAttr() slog.Attr {
 }
 
 type metricValueItem interface {
-	writeValue(name string, w io.Writer) error
+	// writeValue writes the samples in a text format
+	writeValue(name string, w io.Writer, f Format) error
+	// protoMetrics returns the samples as Metrics of the protobuf format
+	protoMetrics(name string) []*dto.Metric
 	valueToString() string
 	logAttr() slog.Attr
 }
@@ -144,36 +357,30 @@ type metricExpirableItem interface {
 
 // metricStringerItem is a stringer metric value with labels
 type metricStringerItem struct {
-	labels   Labels
-	value    fmt.Stringer
-	expireAt time.Time
+	series
+	value         Value
+	emitTimestamp bool
 }
 
-func (m metricStringerItem) writeValue(name string, w io.Writer) error {
+func (m metricStringerItem) writeValue(name string, w io.Writer, f Format) error {
 	io.WriteString(w, name)
 	io.WriteString(w, m.labels.String())
 	io.WriteString(w, " ")
 	io.WriteString(w, m.value.String())
+	if m.emitTimestamp {
+		writeTimestamp(w, m.updatedAt, f)
+	}
 	io.WriteString(w, "\n")
 
 	return nil
 }
 
-func (m metricStringerItem) valueToString() string {
-	return m.value.String()
+func (m metricStringerItem) protoMetrics(string) []*dto.Metric {
+	return []*dto.Metric{protoTimestamp(protoGauge(m.labels, m.value.Float64()), m.updatedAt, m.emitTimestamp)}
 }
 
-func (m metricStringerItem) expired(now time.Time) (bool, string) {
-	if m.expireAt.IsZero() {
-		return false, ""
-	}
-
-	// now >= expireAt
-	if !now.Before(m.expireAt) {
-		return true, m.labels.String()
-	}
-
-	return false, ""
+func (m metricStringerItem) valueToString() string {
+	return m.value.String()
 }
 
 func (m metricStringerItem) logAttr() slog.Attr {
@@ -193,7 +400,7 @@ func (l Labels) String() string {
 
 	kvs := make([]string, 0, len(l))
 	for _, k := range util.Keys(l) {
-		kvs = append(kvs, fmt.Sprintf("%s=%s", k, strconv.Quote(l[k])))
+		kvs = append(kvs, fmt.Sprintf("%s=\"%s\"", k, escapeLabelValue(l[k])))
 	}
 	return "{" + strings.Join(kvs, ",") + "}"
 }
@@ -17,13 +17,13 @@ func TestMetricSet(t *testing.T) {
 	v2 := NewGauge("testValue2", "testHelp2")
 	s.Add(v1, v2)
 
-	v1.Set(Labels{"1": "b", "2": "a"},
+	v1.Set(Labels{"l1": "b", "l2": "a"},
 		RoundFloat64{
 			Value:     1134.43543,
 			Precision: 2,
 		})
 
-	v2.Set(Labels{"1": "b", "2": "a"},
+	v2.Set(Labels{"l1": "b", "l2": "a"},
 		RoundFloat64{
 			Value:     1134.43543,
 			Precision: 2,
@@ -38,10 +38,10 @@ func TestMetricSet(t *testing.T) {
 	got := buf.String()
 	want := `# HELP testValue1 testHelp1
 # TYPE testValue1 gauge
-testValue1{1="b",2="a"} 1134.44
+testValue1{l1="b",l2="a"} 1134.44
 # HELP testValue2 testHelp2
 # TYPE testValue2 gauge
-testValue2{1="b",2="a"} 1134.44
+testValue2{l1="b",l2="a"} 1134.44
 `
 	if got != want {
 		t.Errorf("metricSet.Write() failed: got:%q want:%q", got, want)
@@ -75,7 +75,7 @@ testValue 1134.44
 
 func TestGaugeMetric(t *testing.T) {
 	v := NewGauge("testValue", "testHelp")
-	v.Set(Labels{"1": "b", "2": "a"},
+	v.Set(Labels{"l1": "b", "l2": "a"},
 		RoundFloat64{
 			Value:     1134.43543,
 			Precision: 2,
@@ -91,7 +91,7 @@ func TestGaugeMetric(t *testing.T) {
 
 	want := `# HELP testValue testHelp
 # TYPE testValue gauge
-testValue{1="b",2="a"} 1134.44
+testValue{l1="b",l2="a"} 1134.44
 `
 	if got != want {
 		t.Errorf("gaugeMetric.outputMetric() failed: got:%q want:%q", got, want)
@@ -100,17 +100,17 @@ testValue{1="b",2="a"} 1134.44
 
 func TestGaugeMetricMultipleAndUpdate(t *testing.T) {
 	v := NewGauge("testValue", "testHelp")
-	v.Set(Labels{"1": "b", "2": "a"},
+	v.Set(Labels{"l1": "b", "l2": "a"},
 		RoundFloat64{
 			Value:     1134.43543,
 			Precision: 2,
 		})
-	v.Set(Labels{"1": "b", "2": "a"},
+	v.Set(Labels{"l1": "b", "l2": "a"},
 		RoundFloat64{
 			Value:     2134.43543,
 			Precision: 2,
 		})
-	v.Set(Labels{"1": "c", "2": "a"},
+	v.Set(Labels{"l1": "c", "l2": "a"},
 		RoundFloat64{
 			Value:     3134.43543,
 			Precision: 2,
@@ -125,8 +125,8 @@ func TestGaugeMetricMultipleAndUpdate(t *testing.T) {
 
 	want := `# HELP testValue testHelp
 # TYPE testValue gauge
-testValue{1="b",2="a"} 2134.44
-testValue{1="c",2="a"} 3134.44
+testValue{l1="b",l2="a"} 2134.44
+testValue{l1="c",l2="a"} 3134.44
 `
 	if got != want {
 		t.Errorf("gaugeMetric.outputMetric() failed: got:%q want:%q", got, want)
@@ -141,28 +141,25 @@ func TestMetricExpiration(t *testing.T) {
 		metricType: "testType",
 		values: map[string]metricValueItem{
 			"{expireAt=\"old\"}": metricStringerItem{
-				labels: Labels{"expireAt": "old"},
+				series: series{labels: Labels{"expireAt": "old"}, expireAt: testBeforeNow},
 				value: RoundFloat64{
 					Value:     1134.43543,
 					Precision: 2,
 				},
-				expireAt: testBeforeNow,
 			},
 			"{expireAt=\"now\"}": metricStringerItem{
-				labels: Labels{"expireAt": "now"},
+				series: series{labels: Labels{"expireAt": "now"}, expireAt: testNow},
 				value: RoundFloat64{
 					Value:     1134.43543,
 					Precision: 2,
 				},
-				expireAt: testNow,
 			},
 			"{expireAt=\"new\"}": metricStringerItem{
-				labels: Labels{"expireAt": "new"},
+				series: series{labels: Labels{"expireAt": "new"}, expireAt: testAfterNow},
 				value: RoundFloat64{
 					Value:     1134.43543,
 					Precision: 2,
 				},
-				expireAt: testAfterNow,
 			},
 		},
 	}
@@ -191,7 +188,7 @@ func TestMetricEntityToString(t *testing.T) {
 		metricType: "testType",
 		values: map[string]metricValueItem{
 			"{1=\"b\",2=\"a\"}": metricStringerItem{
-				labels: Labels{"2": "a", "1": "b"},
+				series: series{labels: Labels{"2": "a", "1": "b"}},
 				value: RoundFloat64{
 					Value:     1134.43543,
 					Precision: 2,
@@ -220,7 +217,7 @@ testName{1="b",2="a"} 1134.44
 func TestMetricStringerValueItem(t *testing.T) {
 
 	v := metricStringerItem{
-		labels: Labels{"2": "a", "1": "b"},
+		series: series{labels: Labels{"2": "a", "1": "b"}},
 		value: RoundFloat64{
 			Value:     1134.43543,
 			Precision: 2,
@@ -228,7 +225,7 @@ func TestMetricStringerValueItem(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := v.writeValue("metricName", &buf)
+	err := v.writeValue("metricName", &buf, FormatText)
 	if err != nil {
 		t.Errorf("metricStringerItem.writeValue() failed: %v", err)
 	}
@@ -252,3 +249,176 @@ func TestLabelsString(t *testing.T) {
 		t.Errorf("labels.String() failed: got:%q want:%q", got, want)
 	}
 }
+
+func TestGaugeMetricTimestamp(t *testing.T) {
+	timeNow = func() time.Time { return testBeforeNow }
+	defer func() { timeNow = time.Now }()
+
+	v := NewGauge("testValue", "testHelp", WithTimestamp())
+	v.SetWithTimeout(Labels{"l1": "b"},
+		RoundFloat64{
+			Value:     1134.43543,
+			Precision: 2,
+		}, testAfterNow)
+
+	var buf bytes.Buffer
+	err := v.writeMetric(&buf, testNow, FormatText)
+	if err != nil {
+		t.Errorf("gaugeMetric.writeMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{l1="b"} 1134.44 971176270000
+`
+	if got != want {
+		t.Errorf("gaugeMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+
+	buf.Reset()
+	err = v.writeMetric(&buf, testNow, FormatOpenMetrics)
+	if err != nil {
+		t.Errorf("gaugeMetric.writeMetric() failed: %v", err)
+	}
+
+	got = buf.String()
+	want = `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{l1="b"} 1134.44 971176270.000
+`
+	if got != want {
+		t.Errorf("gaugeMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestLabelsStringEscape(t *testing.T) {
+
+	v := Labels{"place": "居間", "path": `C:\dir`, "quote": "\"a\"\nb"}
+
+	got := v.String()
+	want := `{path="C:\\dir",place="居間",quote="\"a\"\nb"}`
+
+	if got != want {
+		t.Errorf("labels.String() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestLabelsInvalidUTF8(t *testing.T) {
+	v := NewGauge("testValue", "testHelp")
+	v.Set(Labels{"invalid": "a\xffb"}, RoundFloat64{Value: 1})
+	// the same value once sanitized, so the same series
+	v.Set(Labels{"invalid": "a\xfeb"}, RoundFloat64{Value: 2})
+
+	var buf bytes.Buffer
+	if err := v.outputMetric(&buf, testNow); err != nil {
+		t.Errorf("outputMetric() failed: %v", err)
+	}
+	want := "# HELP testValue testHelp\n# TYPE testValue gauge\ntestValue{invalid=\"a\uFFFDb\"} 2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("invalid UTF-8 label failed: got:%q want:%q", got, want)
+	}
+
+	if !v.Delete(Labels{"invalid": "a\xfdb"}) {
+		t.Errorf("Delete() of sanitized series failed")
+	}
+}
+
+func TestHelpEscape(t *testing.T) {
+	v := NewGauge("testValue", "居間の\"温度\"\n\\")
+	v.Set(nil, RoundFloat64{Value: 1, Precision: 0})
+
+	var buf bytes.Buffer
+	v.writeMetric(&buf, testNow, FormatText)
+	want := "# HELP testValue 居間の\"温度\"\\n\\\\\n# TYPE testValue gauge\ntestValue 1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("gaugeMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+
+	buf.Reset()
+	v.writeMetric(&buf, testNow, FormatOpenMetrics)
+	want = "# HELP testValue 居間の\\\"温度\\\"\\n\\\\\n# TYPE testValue gauge\ntestValue 1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("gaugeMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestMetricSetConflict(t *testing.T) {
+	s := MetricSet{}
+	v1 := NewGauge("testValue", "testHelp1")
+	s.Add(v1)
+
+	// adding the same metric again is allowed
+	if err := s.Register(v1); err != nil {
+		t.Errorf("metricSet.Register() failed: %v", err)
+	}
+
+	conflicts := []Metric{
+		NewGauge("testValue", "testHelp2"),
+		NewCounter("testValue", "testHelp2"),
+		NewGauge("testValue2_count", "testHelp2"),
+	}
+	s.Add(NewHistogram("testValue2", "testHelp2", nil))
+
+	for _, it := range conflicts {
+		if err := s.Register(it); err == nil {
+			t.Errorf("metricSet.Register(%s) must fail", it.entityName())
+		}
+	}
+	if len(s) != 2 {
+		t.Errorf("metricSet.Register() changed the set: got:%d want:2", len(s))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("metricSet.Add() must panic")
+		}
+	}()
+	s.Add(conflicts[0])
+}
+
+func TestInvalidNames(t *testing.T) {
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s must panic", name)
+			}
+		}()
+		fn()
+	}
+
+	mustPanic("NewGauge(1st)", func() { NewGauge("1st", "testHelp") })
+	mustPanic("NewGauge(a-b)", func() { NewGauge("a-b", "testHelp") })
+	mustPanic("NewCounter()", func() { NewCounter("", "testHelp") })
+
+	// invalid or reserved label names drop the series
+	g := NewGauge("testValue", "testHelp")
+	g.Set(Labels{"1": "b"}, RoundFloat64{})
+	g.Set(Labels{"__name__": "b"}, RoundFloat64{})
+	h := NewHistogram("testValue2", "testHelp", nil)
+	h.Observe(Labels{"le": "1"}, 1)
+	for _, m := range []Metric{g, h} {
+		var buf bytes.Buffer
+		if err := m.outputMetric(&buf, testNow); err != nil {
+			t.Errorf("outputMetric() failed: %v", err)
+		}
+		if got := buf.String(); got != "" {
+			t.Errorf("%s must drop series with invalid labels: got:%q want:%q", m.entityName(), got, "")
+		}
+	}
+
+	for _, name := range []string{"a", "_a", "a:b", "A_1"} {
+		if !IsValidMetricName(name) {
+			t.Errorf("IsValidMetricName(%q) must be true", name)
+		}
+	}
+	for _, name := range []string{"a", "_a", "A_1"} {
+		if !IsValidLabelName(name) {
+			t.Errorf("IsValidLabelName(%q) must be true", name)
+		}
+	}
+	if IsValidLabelName("a:b") {
+		t.Errorf("IsValidLabelName(%q) must be false", "a:b")
+	}
+}
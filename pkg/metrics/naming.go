@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Naming selects metric names while migrating to new names
+type Naming int
+
+const (
+	// NamingLegacy emits the names as created
+	NamingLegacy Naming = iota
+	// NamingStandard emits the new names instead
+	NamingStandard
+	// NamingBoth emits both of the names for a transition period
+	NamingBoth
+)
+
+var namingNames = []string{"legacy", "standard", "both"}
+
+func (n Naming) String() string {
+	if n < 0 || int(n) >= len(namingNames) {
+		return fmt.Sprintf("Naming(%d)", int(n))
+	}
+	return namingNames[n]
+}
+
+// Set parses the name of naming. It satisfies flag.Value.
+func (n *Naming) Set(s string) error {
+	for i, name := range namingNames {
+		if strings.EqualFold(s, name) {
+			*n = Naming(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown naming: %q (%s)", s, strings.Join(namingNames, ","))
+}
+
+// WithNaming renames the metric, or adds an alias of it, by names which maps
+// a name as created to the new one. The metric is kept as is if not in names.
+func WithNaming(n Naming, names map[string]string) Option {
+	return func(m *metricEntity) {
+		newName, ok := names[m.metricName]
+		if !ok || n == NamingLegacy {
+			return
+		}
+		mustValidMetricName(newName)
+		switch n {
+		case NamingStandard:
+			m.metricName = newName
+		case NamingBoth:
+			m.aliases = append(m.aliases, newName)
+		}
+	}
+}
+
+// names returns the name of the metric followed by its aliases
+func (m *metricEntity) names() []string {
+	return append([]string{m.metricName}, m.aliases...)
+}
+
+// RegisterNamingFlag registers flag `naming` to fs, and returns the naming configured by it.
+func RegisterNamingFlag(fs *flag.FlagSet) *Naming {
+	n := NamingLegacy
+	fs.Var(&n, "naming", "Metric names to emit: legacy, standard or both (for migration)")
+	return &n
+}
@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNaming(t *testing.T) {
+	names := map[string]string{
+		"testValue": "homeprobe_test_value_celsius",
+		"testCount": "homeprobe_test_count",
+	}
+
+	for _, tt := range []struct {
+		naming Naming
+		want   string
+	}{
+		{NamingLegacy, `# HELP testCount_total testHelp
+# TYPE testCount_total counter
+testCount_total{l1="a"} 2
+# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{l1="a"} 1.00
+`},
+		{NamingStandard, `# HELP homeprobe_test_count_total testHelp
+# TYPE homeprobe_test_count_total counter
+homeprobe_test_count_total{l1="a"} 2
+# HELP homeprobe_test_value_celsius testHelp
+# TYPE homeprobe_test_value_celsius gauge
+homeprobe_test_value_celsius{l1="a"} 1.00
+`},
+		{NamingBoth, `# HELP testCount_total testHelp
+# TYPE testCount_total counter
+testCount_total{l1="a"} 2
+# HELP homeprobe_test_count_total testHelp
+# TYPE homeprobe_test_count_total counter
+homeprobe_test_count_total{l1="a"} 2
+# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{l1="a"} 1.00
+# HELP homeprobe_test_value_celsius testHelp
+# TYPE homeprobe_test_value_celsius gauge
+homeprobe_test_value_celsius{l1="a"} 1.00
+`},
+	} {
+		s := MetricSet{}
+		v := NewGauge("testValue", "testHelp", WithNaming(tt.naming, names))
+		c := NewCounter("testCount", "testHelp", WithNaming(tt.naming, names))
+		s.Add(v, c)
+		v.Set(Labels{"l1": "a"}, RoundFloat64{Value: 1, Precision: 2})
+		c.Add(Labels{"l1": "a"}, 2)
+
+		var buf bytes.Buffer
+		if err := s.Write(&buf); err != nil {
+			t.Errorf("MetricSet.Write() failed: %v", err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("naming %s failed: got:%q want:%q", tt.naming, got, tt.want)
+		}
+	}
+
+	// the alias conflicts with the standard name of another metric
+	s := MetricSet{}
+	s.Add(NewGauge("testValue", "testHelp", WithNaming(NamingBoth, names)))
+	if err := s.Register(NewGauge("homeprobe_test_value_celsius", "testHelp")); err == nil {
+		t.Errorf("MetricSet.Register() should detect conflict with alias")
+	}
+
+	var n Naming
+	if err := n.Set("Both"); err != nil || n != NamingBoth {
+		t.Errorf("Naming.Set() failed: got:%v err:%v", n, err)
+	}
+	if err := n.Set("new"); err == nil {
+		t.Errorf("Naming.Set() should fail")
+	}
+}
+
+func TestNamingPrecision(t *testing.T) {
+	names := map[string]string{"testValue": "homeprobe_test_value_celsius"}
+	p := Precisions{}
+	if err := p.Set("homeprobe_test_value_celsius=1"); err != nil {
+		t.Fatalf("Precisions.Set() failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		naming Naming
+		want   string
+	}{
+		{NamingLegacy, `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{l1="a"} 1.25
+`},
+		{NamingStandard, `# HELP homeprobe_test_value_celsius testHelp
+# TYPE homeprobe_test_value_celsius gauge
+homeprobe_test_value_celsius{l1="a"} 1.2
+`},
+		{NamingBoth, `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{l1="a"} 1.2
+# HELP homeprobe_test_value_celsius testHelp
+# TYPE homeprobe_test_value_celsius gauge
+homeprobe_test_value_celsius{l1="a"} 1.2
+`},
+	} {
+		// precisions are given before naming as the commands do
+		v := NewGauge("testValue", "testHelp", WithPrecisions(p), WithNaming(tt.naming, names))
+		v.Set(Labels{"l1": "a"}, RoundFloat64{Value: 1.2468, Precision: 2})
+
+		var buf bytes.Buffer
+		if err := v.outputMetric(&buf, testNow); err != nil {
+			t.Errorf("outputMetric() failed: %v", err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("precision of naming %s failed: got:%q want:%q", tt.naming, got, tt.want)
+		}
+	}
+}
+
+func TestNamingStateSet(t *testing.T) {
+	names := map[string]string{"testState": "homeprobe_test_state"}
+
+	for _, tt := range []struct {
+		naming Naming
+		want   string
+	}{
+		{NamingStandard, `# HELP homeprobe_test_state testHelp
+# TYPE homeprobe_test_state gauge
+homeprobe_test_state{l1="a",testState="a"} 1
+homeprobe_test_state{l1="a",testState="b"} 0
+`},
+		{NamingBoth, `# HELP testState testHelp
+# TYPE testState gauge
+testState{l1="a",testState="a"} 1
+testState{l1="a",testState="b"} 0
+# HELP homeprobe_test_state testHelp
+# TYPE homeprobe_test_state gauge
+homeprobe_test_state{l1="a",testState="a"} 1
+homeprobe_test_state{l1="a",testState="b"} 0
+`},
+	} {
+		// the state label is kept for every name
+		v := NewStateSet("testState", "testHelp", []string{"a", "b"}, WithNaming(tt.naming, names))
+		v.SetState(Labels{"l1": "a"}, "a")
+		// the state label is reserved
+		v.SetState(Labels{"testState": "x"}, "b")
+
+		var buf bytes.Buffer
+		if err := v.outputMetric(&buf, testNow); err != nil {
+			t.Errorf("outputMetric() failed: %v", err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("stateset of naming %s failed: got:%q want:%q", tt.naming, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/walkure/homeprobe/pkg/util"
+)
+
+// Precisions overrides the rounding of float values per metric name,
+// so that precision is configured without editing each Set call.
+type Precisions map[string]precision
+
+type precision struct {
+	digits int
+	// significant means digits are significant digits, otherwise decimal places
+	significant bool
+}
+
+func (p precision) String() string {
+	if p.significant {
+		return strconv.Itoa(p.digits) + "s"
+	}
+	return strconv.Itoa(p.digits)
+}
+
+// round rounds v by the precision
+func (p precision) round(v float64) Value {
+	if p.significant {
+		return SignificantFloat64{Value: v, Digits: p.digits}
+	}
+	return RoundFloat64{Value: v, Precision: p.digits}
+}
+
+// Set parses `<metric name>=<digits>` as decimal places, or
+// `<metric name>=<digits>s` as significant digits. It satisfies flag.Value.
+func (p Precisions) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("precision must be name=digits: %q", s)
+	}
+	if !IsValidMetricName(name) {
+		return fmt.Errorf("invalid metric name: %q", name)
+	}
+
+	var pr precision
+	value, pr.significant = strings.CutSuffix(value, "s")
+	digits, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("precision of %s: %w", name, err)
+	}
+	if pr.significant && digits <= 0 {
+		return fmt.Errorf("significant digits of %s must be positive: %d", name, digits)
+	}
+	pr.digits = digits
+
+	p[name] = pr
+	return nil
+}
+
+func (p Precisions) String() string {
+	var v []string
+	for _, k := range util.Keys(p) {
+		v = append(v, k+"="+p[k].String())
+	}
+	return strings.Join(v, ",")
+}
+
+// RegisterPrecisionFlag registers repeatable flag `precision` to fs,
+// and returns the precisions configured by it.
+func RegisterPrecisionFlag(fs *flag.FlagSet) Precisions {
+	p := Precisions{}
+	fs.Var(p, "precision", "Precision of metric as name=decimal places, or name=<n>s for significant digits (repeatable)")
+	return p
+}
+
+// WithPrecisions overrides the precision of float values set to the metric,
+// if any name the metric is emitted as, including aliases by WithNaming, is in p.
+// The first of the names in p takes precedence.
+func WithPrecisions(p Precisions) Option {
+	return func(m *metricEntity) {
+		m.precisions = p
+	}
+}
+
+// precision returns the precision in m.precisions by the names of the metric,
+// resolved when used so that the order of options does not matter.
+func (m *metricEntity) precision() (precision, bool) {
+	if len(m.precisions) == 0 {
+		return precision{}, false
+	}
+	for _, name := range m.names() {
+		if pr, ok := m.precisions[name]; ok {
+			return pr, true
+		}
+	}
+	return precision{}, false
+}
+
+// round applies the precision of the metric to float values.
+// Integer and boolean values are returned as is.
+func (m *metricEntity) round(v Value) Value {
+	pr, ok := m.precision()
+	if !ok {
+		return v
+	}
+	switch v := v.(type) {
+	case RoundFloat64:
+		return pr.round(v.Value)
+	case SignificantFloat64:
+		return pr.round(v.Value)
+	}
+	return v
+}
@@ -0,0 +1,192 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	loggerFactory "github.com/walkure/homeprobe/pkg/logger"
+)
+
+// procPath is the mount point of procfs, replaceable for testing
+var procPath = "/proc"
+
+// userHZ is USER_HZ of Linux, in which CPU times in /proc are counted
+const userHZ = 100
+
+// processStat is a part of /proc/self/stat
+type processStat struct {
+	cpuSeconds   float64
+	startSeconds float64 // since boot
+	virtualBytes float64
+	rssBytes     float64
+}
+
+func processAvailable() bool {
+	_, err := os.Stat(filepath.Join(procPath, "self", "stat"))
+	return err == nil
+}
+
+func newProcessMetrics(opts ...Option) []Metric {
+	return []Metric{
+		newProcessGauge("process_resident_memory_bytes", "Resident memory size in bytes", 0, func() (float64, error) {
+			s, err := readProcessStat()
+			return s.rssBytes, err
+		}, opts...),
+		newProcessGauge("process_virtual_memory_bytes", "Virtual memory size in bytes", 0, func() (float64, error) {
+			s, err := readProcessStat()
+			return s.virtualBytes, err
+		}, opts...),
+		newProcessGauge("process_open_fds", "Number of open file descriptors", 0, readOpenFDs, opts...),
+		newProcessGauge("process_max_fds", "Maximum number of open file descriptors", 0, readMaxFDs, opts...),
+		newProcessGauge("process_start_time_seconds", "Start time of the process since unix epoch in seconds", 2,
+			readStartTime, append([]Option{WithUnit("seconds")}, opts...)...),
+		newProcessCounter("process_cpu_seconds_total", "Total user and system CPU time spent in seconds", func() (float64, error) {
+			s, err := readProcessStat()
+			return s.cpuSeconds, err
+		}, append([]Option{WithUnit("seconds")}, opts...)...),
+	}
+}
+
+func newProcessGauge(name, help string, precision int, read func() (float64, error), opts ...Option) Metric {
+	g := NewGauge(name, help, opts...).(*metricEntity)
+	g.collect = func() {
+		v, err := read()
+		if err != nil {
+			loggerFactory.GetLogger("metrics").Debug("read process stat",
+				slog.String("metric", name),
+				slog.Any("err", err),
+			)
+			return
+		}
+		g.Set(nil, RoundFloat64{Value: v, Precision: precision})
+	}
+	return g
+}
+
+func newProcessCounter(name, help string, read func() (float64, error), opts ...Option) Metric {
+	c := NewCounter(name, help, opts...).(*counterEntity)
+	c.collect = func() {
+		v, err := read()
+		if err != nil {
+			loggerFactory.GetLogger("metrics").Debug("read process stat",
+				slog.String("metric", name),
+				slog.Any("err", err),
+			)
+			return
+		}
+		c.Set(nil, RoundFloat64{Value: v, Precision: -1})
+	}
+	return c
+}
+
+func readProcessStat() (processStat, error) {
+	data, err := os.ReadFile(filepath.Join(procPath, "self", "stat"))
+	if err != nil {
+		return processStat{}, err
+	}
+	return parseProcessStat(data, os.Getpagesize())
+}
+
+// parseProcessStat parses /proc/[pid]/stat. see proc(5)
+func parseProcessStat(data []byte, pageSize int) (processStat, error) {
+	// comm may contain spaces and parentheses
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return processStat{}, fmt.Errorf("malformed stat: %q", data)
+	}
+	// fields[0] is the 3rd field (state)
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 22 {
+		return processStat{}, fmt.Errorf("too few fields in stat: %d", len(fields))
+	}
+
+	var v [5]float64
+	for j, n := range []int{14, 15, 22, 23, 24} {
+		f, err := strconv.ParseFloat(fields[n-3], 64)
+		if err != nil {
+			return processStat{}, fmt.Errorf("stat field %d: %w", n, err)
+		}
+		v[j] = f
+	}
+	utime, stime, starttime, vsize, rss := v[0], v[1], v[2], v[3], v[4]
+
+	return processStat{
+		cpuSeconds:   (utime + stime) / userHZ,
+		startSeconds: starttime / userHZ,
+		virtualBytes: vsize,
+		rssBytes:     rss * float64(pageSize),
+	}, nil
+}
+
+func readOpenFDs() (float64, error) {
+	entries, err := os.ReadDir(filepath.Join(procPath, "self", "fd"))
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(entries)), nil
+}
+
+func readMaxFDs() (float64, error) {
+	f, err := os.Open(filepath.Join(procPath, "self", "limits"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// Max open files            1024                 4096                 files
+		if rest, ok := strings.CutPrefix(sc.Text(), "Max open files"); ok {
+			fields := strings.Fields(rest)
+			if len(fields) == 0 {
+				break
+			}
+			if fields[0] == "unlimited" {
+				return 0, fmt.Errorf("open files unlimited")
+			}
+			return strconv.ParseFloat(fields[0], 64)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("max open files not found")
+}
+
+func readStartTime() (float64, error) {
+	s, err := readProcessStat()
+	if err != nil {
+		return 0, err
+	}
+	bootTime, err := readBootTime()
+	if err != nil {
+		return 0, err
+	}
+	return bootTime + s.startSeconds, nil
+}
+
+// readBootTime reads the boot time since unix epoch from /proc/stat
+func readBootTime() (float64, error) {
+	f, err := os.Open(filepath.Join(procPath, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if rest, ok := strings.CutPrefix(sc.Text(), "btime "); ok {
+			return strconv.ParseFloat(strings.TrimSpace(rest), 64)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("btime not found")
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"io"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/walkure/homeprobe/pkg/util"
+)
+
+// writeProtoFamily writes the family as a length-delimited MetricFamily
+func writeProtoFamily(w io.Writer, name, help string, metricType dto.MetricType, unit string, metrics []*dto.Metric) error {
+	mf := &dto.MetricFamily{
+		Name:   proto.String(name),
+		Help:   proto.String(help),
+		Type:   metricType.Enum(),
+		Metric: metrics,
+	}
+	if unit != "" {
+		mf.Unit = proto.String(unit)
+	}
+	_, err := protodelim.MarshalTo(w, mf)
+	return err
+}
+
+// protoLabels returns label pairs sorted by name
+func protoLabels(l Labels) []*dto.LabelPair {
+	var ret []*dto.LabelPair
+	for _, k := range util.Keys(l) {
+		ret = append(ret, &dto.LabelPair{
+			Name:  proto.String(k),
+			Value: proto.String(l[k]),
+		})
+	}
+	return ret
+}
+
+// protoGauge returns a Metric of a gauge value
+func protoGauge(l Labels, v float64) *dto.Metric {
+	return &dto.Metric{
+		Label: protoLabels(l),
+		Gauge: &dto.Gauge{Value: proto.Float64(v)},
+	}
+}
+
+// protoTimestamp sets the timestamp of the sample if emit is set
+func protoTimestamp(m *dto.Metric, at time.Time, emit bool) *dto.Metric {
+	if emit {
+		m.TimestampMs = proto.Int64(at.UnixMilli())
+	}
+	return m
+}
+
+// protoMetricType returns MetricType of the metric type.
+// Types unknown to the protobuf format are exposed as gauge.
+func protoMetricType(metricType string) dto.MetricType {
+	switch metricType {
+	case "counter":
+		return dto.MetricType_COUNTER
+	case "summary":
+		return dto.MetricType_SUMMARY
+	case "histogram":
+		return dto.MetricType_HISTOGRAM
+	default:
+		return dto.MetricType_GAUGE
+	}
+}
@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+func readFamilies(t *testing.T, buf *bytes.Buffer) []*dto.MetricFamily {
+	t.Helper()
+
+	r := bufio.NewReader(buf)
+	var ret []*dto.MetricFamily
+	for {
+		mf := &dto.MetricFamily{}
+		if err := protodelim.UnmarshalFrom(r, mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ret
+			}
+			t.Fatalf("protodelim.UnmarshalFrom() failed: %v", err)
+		}
+		ret = append(ret, mf)
+	}
+}
+
+func TestMetricSetProtobuf(t *testing.T) {
+	timeNow = func() time.Time { return testBeforeNow }
+	defer func() { timeNow = time.Now }()
+
+	s := MetricSet{}
+	g := NewGauge("testGauge", "testHelp1", WithUnit("celsius"))
+	c := NewCounter("testCounter", "testHelp2", WithCreated(), WithTimestamp())
+	h := NewHistogram("testHistogram", "testHelp3", []float64{1, 10}, WithCreated(), WithTimestamp())
+	sm := NewSummary("testSummary", "testHelp4", time.Minute, []float64{0.5}, WithCreated())
+	s.Add(g, c, h, sm)
+
+	g.Set(Labels{"l2": "a", "l1": "b"}, RoundFloat64{Value: 1134.43543, Precision: 2})
+	c.Add(nil, 3)
+	h.Observe(nil, 0.5)
+	h.Observe(nil, 5)
+	h.Observe(nil, 50)
+	sm.Observe(nil, 2)
+
+	var buf bytes.Buffer
+	if err := s.WriteFormat(&buf, FormatProtobuf); err != nil {
+		t.Fatalf("metricSet.WriteFormat() failed: %v", err)
+	}
+
+	mfs := readFamilies(t, &buf)
+	if len(mfs) != 4 {
+		t.Fatalf("metricSet.WriteFormat() families: got:%d want:4", len(mfs))
+	}
+
+	// counter
+	if got, want := mfs[0].GetName(), "testCounter_total"; got != want {
+		t.Errorf("counter name failed: got:%q want:%q", got, want)
+	}
+	if got, want := mfs[0].GetType(), dto.MetricType_COUNTER; got != want {
+		t.Errorf("counter type failed: got:%v want:%v", got, want)
+	}
+	if got, want := mfs[0].Metric[0].GetCounter().GetValue(), 3.0; got != want {
+		t.Errorf("counter value failed: got:%v want:%v", got, want)
+	}
+	if got, want := mfs[0].Metric[0].GetCounter().GetCreatedTimestamp().AsTime(), testBeforeNow; !got.Equal(want) {
+		t.Errorf("counter created failed: got:%v want:%v", got, want)
+	}
+	if got, want := mfs[0].Metric[0].GetTimestampMs(), testBeforeNow.UnixMilli(); got != want {
+		t.Errorf("counter timestamp failed: got:%v want:%v", got, want)
+	}
+
+	// gauge
+	if got, want := mfs[1].GetHelp(), "testHelp1"; got != want {
+		t.Errorf("gauge help failed: got:%q want:%q", got, want)
+	}
+	if got, want := mfs[1].GetUnit(), "celsius"; got != want {
+		t.Errorf("gauge unit failed: got:%q want:%q", got, want)
+	}
+	gm := mfs[1].Metric[0]
+	if gm.TimestampMs != nil {
+		t.Errorf("gauge timestamp failed: got:%v want:nil", gm.GetTimestampMs())
+	}
+	if got, want := gm.GetGauge().GetValue(), 1134.44; got != want {
+		t.Errorf("gauge value failed: got:%v want:%v", got, want)
+	}
+	if len(gm.Label) != 2 || gm.Label[0].GetName() != "l1" || gm.Label[0].GetValue() != "b" ||
+		gm.Label[1].GetName() != "l2" || gm.Label[1].GetValue() != "a" {
+		t.Errorf("gauge labels failed: got:%v", gm.Label)
+	}
+
+	// histogram
+	hm := mfs[2].Metric[0].GetHistogram()
+	if got, want := mfs[2].GetType(), dto.MetricType_HISTOGRAM; got != want {
+		t.Errorf("histogram type failed: got:%v want:%v", got, want)
+	}
+	if hm.GetSampleCount() != 3 || hm.GetSampleSum() != 55.5 || len(hm.Bucket) != 2 ||
+		hm.Bucket[0].GetCumulativeCount() != 1 || hm.Bucket[0].GetUpperBound() != 1 ||
+		hm.Bucket[1].GetCumulativeCount() != 2 || hm.Bucket[1].GetUpperBound() != 10 {
+		t.Errorf("histogram value failed: got:%v", hm)
+	}
+	if got, want := hm.GetCreatedTimestamp().AsTime(), testBeforeNow; !got.Equal(want) {
+		t.Errorf("histogram created failed: got:%v want:%v", got, want)
+	}
+	if got, want := mfs[2].Metric[0].GetTimestampMs(), testBeforeNow.UnixMilli(); got != want {
+		t.Errorf("histogram timestamp failed: got:%v want:%v", got, want)
+	}
+
+	// summary
+	sv := mfs[3].Metric[0].GetSummary()
+	if sv.GetSampleCount() != 1 || sv.GetSampleSum() != 2 || len(sv.Quantile) != 1 ||
+		sv.Quantile[0].GetQuantile() != 0.5 || sv.Quantile[0].GetValue() != 2 {
+		t.Errorf("summary value failed: got:%v", sv)
+	}
+	if got, want := sv.GetCreatedTimestamp().AsTime(), testBeforeNow; !got.Equal(want) {
+		t.Errorf("summary created failed: got:%v want:%v", got, want)
+	}
+	if mfs[3].Metric[0].TimestampMs != nil {
+		t.Errorf("summary timestamp failed: got:%v want:nil", mfs[3].Metric[0].GetTimestampMs())
+	}
+}
+
+func TestProtobufValue(t *testing.T) {
+	s := MetricSet{}
+	g := NewGauge("testGauge", "testHelp")
+	s.Add(g)
+
+	g.Set(Labels{"l1": "a"}, SignificantFloat64{Value: 1234.5678, Digits: 3})
+	g.Set(Labels{"l1": "b"}, RoundFloat64{Value: math.NaN(), Precision: 2})
+	g.Set(Labels{"l1": "c"}, Bool(true))
+
+	var buf bytes.Buffer
+	if err := s.WriteFormat(&buf, FormatProtobuf); err != nil {
+		t.Fatalf("metricSet.WriteFormat() failed: %v", err)
+	}
+
+	mfs := readFamilies(t, &buf)
+	if len(mfs) != 1 || len(mfs[0].Metric) != 3 {
+		t.Fatalf("metricSet.WriteFormat() failed: got:%v", mfs)
+	}
+	if got, want := mfs[0].Metric[0].GetGauge().GetValue(), 1230.0; got != want {
+		t.Errorf("significant value failed: got:%v want:%v", got, want)
+	}
+	if got := mfs[0].Metric[1].GetGauge().GetValue(); !math.IsNaN(got) {
+		t.Errorf("NaN value failed: got:%v want:NaN", got)
+	}
+	if got, want := mfs[0].Metric[2].GetGauge().GetValue(), 1.0; got != want {
+		t.Errorf("bool value failed: got:%v want:%v", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Registry is a MetricSet safe for concurrent use, so that metrics can be
+// registered at runtime (e.g. when a new BLE device appears) while being scraped.
+type Registry struct {
+	mu  sync.RWMutex
+	set MetricSet
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		set: MetricSet{},
+	}
+}
+
+// Register adds metrics. see MetricSet.Register
+func (r *Registry) Register(m ...Metric) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.set.Register(m...)
+}
+
+// Add adds metrics. It panics if a metric conflicts with others.
+func (r *Registry) Add(m ...Metric) {
+	if err := r.Register(m...); err != nil {
+		panic(err)
+	}
+}
+
+// Unregister removes the metric, and reports whether it was registered.
+func (r *Registry) Unregister(m Metric) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.set[m.entityName()] != m {
+		return false
+	}
+	delete(r.set, m.entityName())
+	return true
+}
+
+// Merge registers all the metrics of the sets, e.g. from multiple sensor sources.
+// Nothing is registered if any of them conflicts.
+func (r *Registry) Merge(sets ...MetricSet) error {
+	var all []Metric
+	for _, s := range sets {
+		for _, m := range s {
+			all = append(all, m)
+		}
+	}
+	return r.Register(all...)
+}
+
+// Snapshot returns a copy of the registered metrics.
+// Values of the metrics are not copied.
+func (r *Registry) Snapshot() MetricSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.set)
+}
+
+// Write writes metrics in the Prometheus text format
+func (r *Registry) Write(w io.Writer) error {
+	return r.Snapshot().Write(w)
+}
+
+// WriteFormat writes metrics in the specified exposition format
+func (r *Registry) WriteFormat(w io.Writer, f Format) error {
+	return r.Snapshot().WriteFormat(w, f)
+}
+
+// ServeHTTP writes metrics in the format negotiated by the Accept header
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Snapshot().ServeHTTP(w, req)
+}
+
+// satisfy slog.LogValuer interface
+func (r *Registry) LogValue() slog.Value {
+	return r.Snapshot().LogValue()
+}
+
+// OnExpire registers fn to all the metrics registered at present
+func (r *Registry) OnExpire(fn ExpireFunc) {
+	r.Snapshot().OnExpire(fn)
+}
+
+// Expire removes the series expired at now
+func (r *Registry) Expire(now time.Time) {
+	r.Snapshot().Expire(now)
+}
+
+// StartReaper starts a goroutine removing expired series every interval.
+// Metrics registered later are also reaped. The goroutine stops when ctx is done.
+func (r *Registry) StartReaper(ctx context.Context, interval time.Duration) {
+	go reap(ctx, interval, r.Expire)
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	v1 := NewGauge("testValue1", "testHelp1")
+	r.Add(v1)
+
+	s := MetricSet{}
+	v2 := NewCounter("testValue2", "testHelp2")
+	s.Add(v2)
+	if err := r.Merge(s); err != nil {
+		t.Errorf("registry.Merge() failed: %v", err)
+	}
+	if err := r.Merge(MetricSet{"dup": NewGauge("testValue1", "testHelp1")}); err == nil {
+		t.Errorf("registry.Merge() must fail on conflict")
+	}
+
+	v1.Set(nil, RoundFloat64{Value: 1, Precision: 0})
+	v2.Inc(nil)
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Errorf("registry.Write() failed: %v", err)
+	}
+	want := `# HELP testValue2_total testHelp2
+# TYPE testValue2_total counter
+testValue2_total 1
+# HELP testValue1 testHelp1
+# TYPE testValue1 gauge
+testValue1 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("registry.Write() failed: got:%q want:%q", got, want)
+	}
+
+	if !r.Unregister(v2) {
+		t.Errorf("registry.Unregister() must report registered metric")
+	}
+	if r.Unregister(v2) {
+		t.Errorf("registry.Unregister() must not report unregistered metric")
+	}
+	if got := len(r.Snapshot()); got != 1 {
+		t.Errorf("registry.Snapshot() failed: got:%d want:1", got)
+	}
+}
+
+// run with `go test -race`
+func TestRegistryConcurrent(t *testing.T) {
+	r := NewRegistry()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g := NewGauge(fmt.Sprintf("testValue%d", i), "testHelp")
+			c := NewCounter(fmt.Sprintf("testCounter%d", i), "testHelp")
+			r.Add(g, c)
+			for j := 0; j < 100; j++ {
+				g.Set(Labels{"device": fmt.Sprint(j % 4)}, RoundFloat64{Value: float64(j), Precision: 0})
+				c.Inc(nil)
+				r.WriteFormat(io.Discard, Format(j%3))
+				logger.Info("metrics", slog.Any("registry", r))
+			}
+		}()
+	}
+	wg.Wait()
+
+	var buf bytes.Buffer
+	r.Write(&buf)
+	for i := 0; i < 8; i++ {
+		want := fmt.Sprintf("testCounter%d_total 100\n", i)
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("registry concurrent write failed: %q not in %q", want, buf.String())
+		}
+	}
+}
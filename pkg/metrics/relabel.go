@@ -0,0 +1,290 @@
+package metrics
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	loggerFactory "github.com/walkure/homeprobe/pkg/logger"
+)
+
+// Relabeler adds constant labels and rewrites labels of series by rules.
+// It must not be modified after passed to WithRelabel.
+type Relabeler struct {
+	constLabels Labels
+	rules       []relabelRule
+}
+
+type relabelAction string
+
+const (
+	// rename <label> <new label>
+	relabelRename relabelAction = "rename"
+	// labeldrop <label regex>
+	relabelLabelDrop relabelAction = "labeldrop"
+	// replace <label> <regex> <replacement> [<target label>]
+	relabelReplace relabelAction = "replace"
+	// drop <label> <regex>
+	relabelDrop relabelAction = "drop"
+)
+
+type relabelRule struct {
+	action relabelAction
+	label  string
+	regex  *regexp.Regexp
+	target string
+	// replacement may refer to capture groups of regex like $1
+	replacement string
+}
+
+func NewRelabeler() *Relabeler {
+	return &Relabeler{
+		constLabels: Labels{},
+	}
+}
+
+// WithRelabel applies the relabeler to labels when the metric is set.
+// The metric panics on creation if the relabeler adds a label it reserves.
+func WithRelabel(r *Relabeler) Option {
+	return func(m *metricEntity) {
+		m.relabel = r
+	}
+}
+
+// AddConstLabel parses `name=value` and adds it as a constant label
+func (r *Relabeler) AddConstLabel(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("label must be name=value: %q", s)
+	}
+	if !IsValidLabelName(name) {
+		return fmt.Errorf("invalid label name: %q", name)
+	}
+	if slices.Contains(reservedLabelNames, name) {
+		return fmt.Errorf("reserved label name: %q", name)
+	}
+	r.constLabels[name] = value
+	return nil
+}
+
+// AddRule parses a rule separated by spaces, and adds it. Regexes are fully anchored.
+// A field containing spaces can be quoted as a Go string literal, e.g. "living room".
+//
+//	rename <label> <new label>
+//	labeldrop <label regex>
+//	replace <label> <regex> <replacement> [<target label>]
+//	drop <label> <regex>
+func (r *Relabeler) AddRule(s string) error {
+	fields, err := splitRule(s)
+	if err != nil {
+		return fmt.Errorf("relabel rule %q: %w", s, err)
+	}
+	if len(fields) < 2 {
+		return fmt.Errorf("relabel rule too short: %q", s)
+	}
+
+	rule := relabelRule{
+		action: relabelAction(fields[0]),
+		label:  fields[1],
+	}
+
+	switch rule.action {
+	case relabelRename:
+		if len(fields) != 3 {
+			return fmt.Errorf("rename needs <label> <new label>: %q", s)
+		}
+		rule.target = fields[2]
+	case relabelLabelDrop:
+		if len(fields) != 2 {
+			return fmt.Errorf("labeldrop needs <label regex>: %q", s)
+		}
+		rule.regex, err = compileAnchored(fields[1])
+		rule.label = ""
+	case relabelReplace:
+		if len(fields) != 4 && len(fields) != 5 {
+			return fmt.Errorf("replace needs <label> <regex> <replacement> [<target label>]: %q", s)
+		}
+		rule.regex, err = compileAnchored(fields[2])
+		rule.replacement = fields[3]
+		rule.target = rule.label
+		if len(fields) == 5 {
+			rule.target = fields[4]
+		}
+	case relabelDrop:
+		if len(fields) != 3 {
+			return fmt.Errorf("drop needs <label> <regex>: %q", s)
+		}
+		rule.regex, err = compileAnchored(fields[2])
+	default:
+		return fmt.Errorf("unknown relabel action: %q", fields[0])
+	}
+	if err != nil {
+		return fmt.Errorf("relabel rule %q: %w", s, err)
+	}
+
+	for _, name := range []string{rule.label, rule.target} {
+		if name != "" && !IsValidLabelName(name) {
+			return fmt.Errorf("relabel rule %q: invalid label name: %q", s, name)
+		}
+	}
+	if slices.Contains(reservedLabelNames, rule.target) {
+		return fmt.Errorf("relabel rule %q: reserved label name: %q", s, rule.target)
+	}
+
+	r.rules = append(r.rules, rule)
+	return nil
+}
+
+// splitRule splits s into fields separated by spaces.
+// A field starting with a double quote or a backquote is unquoted as a Go string literal.
+func splitRule(s string) ([]string, error) {
+	var fields []string
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return fields, nil
+		}
+
+		if s[0] != '"' && s[0] != '`' {
+			i := strings.IndexFunc(s, unicode.IsSpace)
+			if i < 0 {
+				i = len(s)
+			}
+			fields = append(fields, s[:i])
+			s = s[i:]
+			continue
+		}
+
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("unterminated quote: %s", s)
+		}
+		field, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, err
+		}
+		s = s[len(quoted):]
+		if s != "" && !unicode.IsSpace(rune(s[0])) {
+			return nil, fmt.Errorf("no space after quoted field: %s", quoted)
+		}
+		fields = append(fields, field)
+	}
+}
+
+// reservedLabelNames are used by histograms and summaries. A stateset reserves the name
+// it is created with as its state label, so a relabeler adding it panics on creation.
+var reservedLabelNames = []string{"le", "quantile"}
+
+func compileAnchored(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// Apply returns labels with constant labels and rules applied,
+// and false if the series is to be dropped. labels are not modified.
+// Labels of the series take precedence over constant labels.
+func (r *Relabeler) Apply(labels Labels) (Labels, bool) {
+	if r == nil {
+		return labels, true
+	}
+
+	ret := maps.Clone(r.constLabels)
+	maps.Copy(ret, labels)
+
+	for _, rule := range r.rules {
+		switch rule.action {
+		case relabelRename:
+			if v, ok := ret[rule.label]; ok {
+				delete(ret, rule.label)
+				ret[rule.target] = v
+			}
+		case relabelLabelDrop:
+			maps.DeleteFunc(ret, func(k, _ string) bool {
+				return rule.regex.MatchString(k)
+			})
+		case relabelReplace:
+			v, ok := ret[rule.label]
+			if !ok {
+				continue
+			}
+			if m := rule.regex.FindStringSubmatchIndex(v); m != nil {
+				ret[rule.target] = string(rule.regex.ExpandString(nil, rule.replacement, v, m))
+			}
+		case relabelDrop:
+			if rule.regex.MatchString(ret[rule.label]) {
+				return nil, false
+			}
+		}
+	}
+	return ret, true
+}
+
+// validate returns an error if the relabeler adds a label named one of reserved
+func (r *Relabeler) validate(reserved ...string) error {
+	if r == nil {
+		return nil
+	}
+	for name := range r.constLabels {
+		if slices.Contains(reserved, name) {
+			return fmt.Errorf("reserved constant label name: %q", name)
+		}
+	}
+	for _, rule := range r.rules {
+		if slices.Contains(reserved, rule.target) {
+			return fmt.Errorf("relabel rule %s: reserved label name: %q", rule.action, rule.target)
+		}
+	}
+	return nil
+}
+
+// mustValidRelabel panics if the relabeler of the metric adds a label named one of reserved,
+// which would drop all the series of the metric.
+func (m *metricEntity) mustValidRelabel(reserved ...string) {
+	if err := m.relabel.validate(reserved...); err != nil {
+		panic(fmt.Sprintf("metric %s: %v", m.metricName, err))
+	}
+}
+
+// seriesLabels returns labels of the series to be stored, and false if the series
+// is dropped by the relabeler or has an invalid or a reserved label name.
+// Invalid UTF-8 sequences of values are replaced before the key is built.
+func (m *metricEntity) seriesLabels(labels Labels, reserved ...string) (Labels, bool) {
+	labels, ok := m.relabel.Apply(labels)
+	if !ok {
+		return nil, false
+	}
+	if labels == nil {
+		labels = noneLabels
+	}
+	labels = sanitizeLabels(labels)
+	if err := validateLabels(labels, reserved...); err != nil {
+		m.dropSeries(labels, err)
+		return nil, false
+	}
+	return labels, true
+}
+
+// dropSeries logs the series not stored because of err
+func (m *metricEntity) dropSeries(labels Labels, err error) {
+	loggerFactory.GetLogger("metrics").Warn("series dropped",
+		slog.String("metric", m.metricName),
+		slog.String("label", labels.String()),
+		slog.Any("error", err),
+	)
+}
+
+// RegisterRelabelFlags registers repeatable flags `label` and `relabel` to fs,
+// and returns the relabeler configured by them.
+func RegisterRelabelFlags(fs *flag.FlagSet) *Relabeler {
+	r := NewRelabeler()
+	fs.Func("label", "Constant label name=value added to all the series (repeatable)", r.AddConstLabel)
+	fs.Func("relabel", "Relabel rule (repeatable): 'rename <label> <new label>', 'labeldrop <label regex>', "+
+		"'replace <label> <regex> <replacement> [<target label>]' or 'drop <label> <regex>'. "+
+		"Quote a field containing spaces like \"living room\"", r.AddRule)
+	return r
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRelabel(t *testing.T) {
+	r := NewRelabeler()
+	for _, s := range []string{"room=bedroom", "floor=2"} {
+		if err := r.AddConstLabel(s); err != nil {
+			t.Fatalf("AddConstLabel(%q) failed: %v", s, err)
+		}
+	}
+	for _, s := range []string{
+		"rename place location",
+		"replace wosensor_id AA:BB:CC:DD:EE:(FF) balcony_$1",
+		"replace wosensor_id 11:22:33:44:55:66 kitchen",
+		"drop wosensor_id 00:.*",
+		"labeldrop wosensor_type|unused",
+		`replace location "living room|lounge" "living room"`,
+		"replace location `guest (room)` `spare $1`",
+	} {
+		if err := r.AddRule(s); err != nil {
+			t.Fatalf("AddRule(%q) failed: %v", s, err)
+		}
+	}
+
+	v := NewGauge("testValue", "testHelp", WithRelabel(r))
+	v.Set(Labels{"place": "inside"}, RoundFloat64{Value: 1})
+	v.Set(Labels{"wosensor_id": "AA:BB:CC:DD:EE:FF", "wosensor_type": "THO"}, RoundFloat64{Value: 2})
+	v.Set(Labels{"wosensor_id": "11:22:33:44:55:66", "floor": "1"}, RoundFloat64{Value: 3})
+	v.Set(Labels{"wosensor_id": "00:11:22:33:44:55"}, RoundFloat64{Value: 4})
+	v.Set(Labels{"wosensor_id": "AA:BB:CC:DD:EE:FF0"}, RoundFloat64{Value: 5})
+	v.Set(Labels{"location": "lounge"}, RoundFloat64{Value: 6})
+	v.Set(Labels{"place": "guest room"}, RoundFloat64{Value: 7})
+
+	var buf bytes.Buffer
+	if err := v.outputMetric(&buf, testNow); err != nil {
+		t.Errorf("outputMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{floor="1",room="bedroom",wosensor_id="kitchen"} 3
+testValue{floor="2",location="inside",room="bedroom"} 1
+testValue{floor="2",location="living room",room="bedroom"} 6
+testValue{floor="2",location="spare room",room="bedroom"} 7
+testValue{floor="2",room="bedroom",wosensor_id="AA:BB:CC:DD:EE:FF0"} 5
+testValue{floor="2",room="bedroom",wosensor_id="balcony_FF"} 2
+`
+	if got != want {
+		t.Errorf("relabeled metric failed: got:%q want:%q", got, want)
+	}
+
+	if !v.Delete(Labels{"place": "inside"}) {
+		t.Errorf("Delete() of relabeled series failed")
+	}
+}
+
+func TestRelabelInvalid(t *testing.T) {
+	r := NewRelabeler()
+	for _, s := range []string{"room", "1room=bedroom", "le=1", "quantile=0.5"} {
+		if err := r.AddConstLabel(s); err == nil {
+			t.Errorf("AddConstLabel(%q) should fail", s)
+		}
+	}
+	for _, s := range []string{
+		"",
+		"rename place",
+		"rename place 1place",
+		"labeldrop a b",
+		"replace place (inside outside",
+		"replace place inside",
+		"drop place",
+		"keep place inside",
+		"rename place le",
+		"replace place (.*) $1 quantile",
+		"rename place __name__",
+		`replace place "inside outside`,
+		`replace place "inside"outside x`,
+	} {
+		if err := r.AddRule(s); err == nil {
+			t.Errorf("AddRule(%q) should fail", s)
+		}
+	}
+}
+
+func TestRelabelReserved(t *testing.T) {
+	mustPanic := func(name string, r *Relabeler, fn func(opt Option)) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s with a reserved label must panic", name)
+			}
+		}()
+		fn(WithRelabel(r))
+	}
+
+	renamed := NewRelabeler()
+	if err := renamed.AddRule("rename place testState"); err != nil {
+		t.Fatalf("AddRule() failed: %v", err)
+	}
+	mustPanic("NewStateSet()", renamed, func(opt Option) {
+		NewStateSet("testState", "testHelp", []string{"a", "b"}, opt)
+	})
+	// not reserved by others
+	NewGauge("testState", "testHelp", WithRelabel(renamed))
+
+	constant := NewRelabeler()
+	if err := constant.AddConstLabel("testState=a"); err != nil {
+		t.Fatalf("AddConstLabel() failed: %v", err)
+	}
+	mustPanic("NewStateSet()", constant, func(opt Option) {
+		NewStateSet("testState", "testHelp", []string{"a", "b"}, opt)
+	})
+
+	// AddRule rejects the names reserved by histograms and summaries, so bypass it
+	target := &Relabeler{constLabels: Labels{}, rules: []relabelRule{{action: relabelRename, label: "place", target: "le"}}}
+	mustPanic("NewHistogram()", target, func(opt Option) {
+		NewHistogram("testValue", "testHelp", nil, opt)
+	})
+	target.rules[0].target = "quantile"
+	mustPanic("NewSummary()", target, func(opt Option) {
+		NewSummary("testValue", "testHelp", time.Minute, []float64{0.5}, opt)
+	})
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"math"
+	rtmetrics "runtime/metrics"
+)
+
+// gcPauseBuckets are buckets the GC pause histogram of the runtime is folded into
+var gcPauseBuckets = ExponentialBuckets(1e-5, 4, 8)
+
+// NewRuntimeMetrics creates metrics of the Go runtime and of the process,
+// whose values are read on every write. Process metrics are read from
+// /proc/self, and omitted where it is not available.
+func NewRuntimeMetrics(opts ...Option) []Metric {
+	ret := []Metric{
+		newRuntimeGauge("go_goroutines", "Number of goroutines", "/sched/goroutines:goroutines", opts...),
+		newRuntimeGauge("go_heap_objects_bytes", "Memory occupied by live and unswept heap objects",
+			"/memory/classes/heap/objects:bytes", opts...),
+		newRuntimeGauge("go_heap_goal_bytes", "Heap size target for the end of the GC cycle",
+			"/gc/heap/goal:bytes", opts...),
+		newRuntimeGauge("go_memory_total_bytes", "All memory mapped by the Go runtime",
+			"/memory/classes/total:bytes", opts...),
+		newRuntimeCounter("go_gc_cycles_total", "Completed GC cycles", "/gc/cycles/total:gc-cycles", opts...),
+		newGCPauseHistogram(opts...),
+	}
+
+	if processAvailable() {
+		ret = append(ret, newProcessMetrics(opts...)...)
+	}
+
+	return ret
+}
+
+// readRuntime reads a runtime metric. ok is false if not supported.
+func readRuntime(name string) (rtmetrics.Value, bool) {
+	s := []rtmetrics.Sample{{Name: name}}
+	rtmetrics.Read(s)
+	return s[0].Value, s[0].Value.Kind() != rtmetrics.KindBad
+}
+
+// runtimeFloat64 returns the value of a scalar runtime metric
+func runtimeFloat64(v rtmetrics.Value) float64 {
+	switch v.Kind() {
+	case rtmetrics.KindUint64:
+		return float64(v.Uint64())
+	case rtmetrics.KindFloat64:
+		return v.Float64()
+	}
+	return math.NaN()
+}
+
+func newRuntimeGauge(name, help, key string, opts ...Option) Metric {
+	g := NewGauge(name, help, opts...).(*metricEntity)
+	g.collect = func() {
+		if v, ok := readRuntime(key); ok {
+			g.Set(nil, RoundFloat64{Value: runtimeFloat64(v), Precision: 0})
+		}
+	}
+	return g
+}
+
+func newRuntimeCounter(name, help, key string, opts ...Option) Metric {
+	c := NewCounter(name, help, opts...).(*counterEntity)
+	c.collect = func() {
+		if v, ok := readRuntime(key); ok {
+			c.Set(nil, RoundFloat64{Value: runtimeFloat64(v), Precision: 0})
+		}
+	}
+	return c
+}
+
+func newGCPauseHistogram(opts ...Option) Metric {
+	h := NewHistogram("go_gc_pauses_seconds", "Stop-the-world pauses for GC. _sum is approximated by bucket bounds",
+		gcPauseBuckets, append([]Option{WithUnit("seconds")}, opts...)...).(*histogramEntity)
+	h.collect = func() {
+		// /gc/pauses:seconds is the name before Go 1.22
+		for _, key := range []string{"/sched/pauses/total/gc:seconds", "/gc/pauses:seconds"} {
+			if v, ok := readRuntime(key); ok && v.Kind() == rtmetrics.KindFloat64Histogram {
+				h.setHistogram(v.Float64Histogram())
+				return
+			}
+		}
+	}
+	return h
+}
+
+// setHistogram replaces the series without labels by the runtime histogram,
+// whose buckets are folded into the buckets of h.
+func (h *histogramEntity) setHistogram(rh *rtmetrics.Float64Histogram) {
+	labels, ok := h.seriesLabels(nil, "le")
+	if !ok {
+		return
+	}
+
+	item := &metricHistogramItem{
+		series:  series{labels: labels, updatedAt: timeNow()},
+		buckets: h.buckets,
+		counts:  make([]uint64, len(h.buckets)),
+	}
+	for i, n := range rh.Counts {
+		if n == 0 {
+			continue
+		}
+		// the runtime bucket is [lower, upper)
+		lower, upper := rh.Buckets[i], rh.Buckets[i+1]
+		bound := upper
+		if math.IsInf(upper, 1) {
+			bound = lower
+		}
+		item.count += n
+		item.sum += bound * float64(n)
+		for j, le := range h.buckets {
+			if upper <= le {
+				item.counts[j] += n
+				break
+			}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.values[labels.String()] = item
+}
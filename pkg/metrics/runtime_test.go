@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRuntimeMetrics(t *testing.T) {
+	s := MetricSet{}
+	s.Add(NewRuntimeMetrics()...)
+
+	var buf bytes.Buffer
+	if err := s.WriteFormat(&buf, FormatOpenMetrics); err != nil {
+		t.Errorf("WriteFormat() failed: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"# TYPE go_goroutines gauge\n",
+		"# TYPE go_gc_cycles counter\n",
+		"# TYPE go_gc_pauses_seconds histogram\n",
+		"go_gc_pauses_seconds_bucket{le=\"+Inf\"} ",
+		"# TYPE go_heap_objects_bytes gauge\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("runtime metrics failed: %q not in %q", want, got)
+		}
+	}
+}
+
+func TestProcessMetrics(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"stat": "cpu  1 2 3 4\nbtime 971176200\nprocesses 100\n",
+		"self/stat": "1234 (probe (x) y) S 1 1234 1234 0 -1 4194560 1000 0 0 0 " +
+			"150 50 0 0 20 0 8 0 1000 104857600 2560 18446744073709551615\n",
+		"self/limits": "Limit                     Soft Limit           Hard Limit           Units     \n" +
+			"Max open files            1024                 4096                 files     \n",
+		"self/fd/0": "",
+		"self/fd/1": "",
+		"self/fd/2": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orig := procPath
+	procPath = dir
+	defer func() { procPath = orig }()
+
+	s := MetricSet{}
+	s.Add(newProcessMetrics()...)
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Errorf("Write() failed: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"process_cpu_seconds_total 2\n",
+		"process_max_fds 1024\n",
+		"process_open_fds 3\n",
+		"process_start_time_seconds 971176210.00\n",
+		"process_virtual_memory_bytes 104857600\n",
+		"process_resident_memory_bytes " + RoundFloat64{Value: float64(2560 * os.Getpagesize())}.String() + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("process metrics failed: %q not in %q", want, got)
+		}
+	}
+}
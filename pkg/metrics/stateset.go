@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// StateSet exposes which one of the states is active
+type StateSet interface {
+	Metric
+	SetState(labels Labels, state string)
+	SetStateWithTimeout(labels Labels, state string, expireAt time.Time)
+}
+
+// NewStateSet creates a stateset. Each state is exposed as a series labeled
+// `<name>="<state>"`, whose value is 1 if active, 0 otherwise.
+// The label keeps name even if the metric is renamed or aliased by WithNaming,
+// so that the series of every name have the same labels.
+// It panics if the relabeler given by WithRelabel adds the label.
+// It is exposed as a gauge in the formats other than OpenMetrics.
+func NewStateSet(name, help string, states []string, opts ...Option) StateSet {
+	mustValidMetricName(name)
+	if len(states) == 0 {
+		panic(fmt.Sprintf("stateset %s has no states", name))
+	}
+	s := &stateSetEntity{
+		metricEntity: metricEntity{
+			metricName: name,
+			help:       help,
+			values:     make(map[string]metricValueItem),
+			metricType: "stateset",
+		},
+		states:     slices.Clone(states),
+		stateLabel: name,
+	}
+	for _, opt := range opts {
+		opt(&s.metricEntity)
+	}
+	s.mustValidRelabel(s.stateLabel)
+	return s
+}
+
+type stateSetEntity struct {
+	metricEntity
+	states []string
+	// stateLabel is the name of the label of states, reserved for them
+	stateLabel string
+}
+
+// SetState activates the state. Unknown states deactivate all of them.
+func (s *stateSetEntity) SetState(labels Labels, state string) {
+	s.SetStateWithTimeout(labels, state, time.Time{})
+}
+
+func (s *stateSetEntity) SetStateWithTimeout(labels Labels, state string, expireAt time.Time) {
+	s.setIndex(labels, slices.Index(s.states, state), expireAt)
+}
+
+// Set activates the state indexed by value.
+func (s *stateSetEntity) Set(labels Labels, value Value) {
+	s.setIndex(labels, int(value.Float64()), time.Time{})
+}
+
+// SetWithTimeout activates the state indexed by value.
+func (s *stateSetEntity) SetWithTimeout(labels Labels, value Value, expireAt time.Time) {
+	s.setIndex(labels, int(value.Float64()), expireAt)
+}
+
+func (s *stateSetEntity) setIndex(labels Labels, index int, expireAt time.Time) {
+	labels, ok := s.seriesLabels(labels, s.stateLabel)
+	if !ok {
+		return
+	}
+	if index < 0 || index >= len(s.states) {
+		index = -1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[labels.String()] = metricStateSetItem{
+		series:     series{labels: labels, expireAt: expireAt, updatedAt: timeNow()},
+		states:     s.states,
+		stateLabel: s.stateLabel,
+		active:     index,
+	}
+}
+
+// metricStateSetItem is a stateset value with labels
+type metricStateSetItem struct {
+	series
+	states     []string
+	stateLabel string
+	active     int // -1 if none
+}
+
+func (m metricStateSetItem) stateValue(i int) float64 {
+	if i == m.active {
+		return 1
+	}
+	return 0
+}
+
+func (m metricStateSetItem) writeValue(name string, w io.Writer, f Format) error {
+	for i, state := range m.states {
+		writeSample(w, name, m.labels.with(m.stateLabel, state), formatFloat(m.stateValue(i)))
+	}
+	return nil
+}
+
+func (m metricStateSetItem) protoMetrics(string) []*dto.Metric {
+	var ret []*dto.Metric
+	for i, state := range m.states {
+		ret = append(ret, protoGauge(m.labels.with(m.stateLabel, state), m.stateValue(i)))
+	}
+	return ret
+}
+
+func (m metricStateSetItem) valueToString() string {
+	if m.active < 0 {
+		return ""
+	}
+	return m.states[m.active]
+}
+
+func (m metricStateSetItem) logAttr() slog.Attr {
+	return slog.Group("metric",
+		m.labels.LogAttr(),
+		slog.String("value", m.valueToString()),
+	)
+}
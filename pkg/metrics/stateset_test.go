@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStateSetMetric(t *testing.T) {
+	v := NewStateSet("testValue", "testHelp", []string{"low", "high"})
+	v.SetState(Labels{"l1": "b"}, "high")
+	v.Set(Labels{"l1": "c"}, RoundFloat64{Value: 0})
+	v.SetState(Labels{"l1": "d"}, "unknown")
+
+	var buf bytes.Buffer
+	err := v.writeMetric(&buf, testNow, FormatOpenMetrics)
+	if err != nil {
+		t.Errorf("stateSetMetric.writeMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue stateset
+testValue{l1="b",testValue="low"} 0
+testValue{l1="b",testValue="high"} 1
+testValue{l1="c",testValue="low"} 1
+testValue{l1="c",testValue="high"} 0
+testValue{l1="d",testValue="low"} 0
+testValue{l1="d",testValue="high"} 0
+`
+	if got != want {
+		t.Errorf("stateSetMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+
+	buf.Reset()
+	v.SetState(Labels{"l1": "b"}, "low")
+	err = v.writeMetric(&buf, testNow, FormatText)
+	if err != nil {
+		t.Errorf("stateSetMetric.writeMetric() failed: %v", err)
+	}
+
+	got = buf.String()
+	want = `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{l1="b",testValue="low"} 1
+testValue{l1="b",testValue="high"} 0
+testValue{l1="c",testValue="low"} 1
+testValue{l1="c",testValue="high"} 0
+testValue{l1="d",testValue="low"} 0
+testValue{l1="d",testValue="high"} 0
+`
+	if got != want {
+		t.Errorf("stateSetMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+}
@@ -0,0 +1,225 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Summary calculates quantiles of the observations within a sliding window
+type Summary interface {
+	Metric
+	Observe(labels Labels, value float64)
+	ObserveWithTimeout(labels Labels, value float64, expireAt time.Time)
+}
+
+// DefQuantiles are default quantiles of a summary
+var DefQuantiles = []float64{0.5, 0.9, 0.99}
+
+// DefWindow is the default sliding window of a summary
+const DefWindow = 10 * time.Minute
+
+// NewSummary creates a summary. Quantiles are calculated from the observations
+// within window; _sum and _count are cumulative.
+func NewSummary(name, help string, window time.Duration, quantiles []float64, opts ...Option) Summary {
+	mustValidMetricName(name)
+	if window <= 0 {
+		window = DefWindow
+	}
+	if quantiles == nil {
+		quantiles = DefQuantiles
+	}
+	quantiles = slices.Clone(quantiles)
+	slices.Sort(quantiles)
+	for _, q := range quantiles {
+		if q < 0 || q > 1 {
+			panic(fmt.Sprintf("summary %s quantile out of range: %v", name, q))
+		}
+	}
+
+	s := &summaryEntity{
+		metricEntity: metricEntity{
+			metricName: name,
+			help:       help,
+			values:     make(map[string]metricValueItem),
+			metricType: "summary",
+		},
+		window:    window,
+		quantiles: quantiles,
+	}
+	for _, opt := range opts {
+		opt(&s.metricEntity)
+	}
+	s.mustValidRelabel("quantile")
+	return s
+}
+
+type summaryEntity struct {
+	metricEntity
+	window    time.Duration
+	quantiles []float64
+}
+
+func (s *summaryEntity) Observe(labels Labels, value float64) {
+	s.ObserveWithTimeout(labels, value, time.Time{})
+}
+
+func (s *summaryEntity) ObserveWithTimeout(labels Labels, value float64, expireAt time.Time) {
+	labels, ok := s.seriesLabels(labels, "quantile")
+	if !ok {
+		return
+	}
+	key := labels.String()
+	now := timeNow()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.values[key].(*metricSummaryItem)
+	if !ok {
+		item = &metricSummaryItem{
+			series:    series{labels: labels},
+			window:    s.window,
+			quantiles: s.quantiles,
+			created:   now,
+		}
+		s.values[key] = item
+	}
+	item.prune(now)
+	item.samples = append(item.samples, summarySample{at: now, value: value})
+	item.count++
+	item.sum += value
+	item.expireAt = expireAt
+	item.updatedAt = now
+	item.emitCreated = s.emitCreated
+	item.emitTimestamp = s.emitTimestamp
+}
+
+// Set records value as an observation.
+func (s *summaryEntity) Set(labels Labels, value Value) {
+	s.ObserveWithTimeout(labels, value.Float64(), time.Time{})
+}
+
+// SetWithTimeout records value as an observation.
+func (s *summaryEntity) SetWithTimeout(labels Labels, value Value, expireAt time.Time) {
+	s.ObserveWithTimeout(labels, value.Float64(), expireAt)
+}
+
+type summarySample struct {
+	at    time.Time
+	value float64
+}
+
+// metricSummaryItem is a summary with labels. guarded by metricEntity.mu
+type metricSummaryItem struct {
+	series
+	window        time.Duration
+	quantiles     []float64
+	samples       []summarySample // ordered by time
+	count         uint64
+	sum           float64
+	created       time.Time
+	emitCreated   bool
+	emitTimestamp bool
+}
+
+// prune drops observations older than the window
+func (m *metricSummaryItem) prune(now time.Time) {
+	oldest := now.Add(-m.window)
+	i := 0
+	for i < len(m.samples) && m.samples[i].at.Before(oldest) {
+		i++
+	}
+	if i > 0 {
+		m.samples = slices.Delete(m.samples, 0, i)
+	}
+}
+
+// quantileValues returns the values of quantiles by nearest-rank method
+func (m *metricSummaryItem) quantileValues() []float64 {
+	ret := make([]float64, len(m.quantiles))
+	if len(m.samples) == 0 {
+		for i := range ret {
+			ret[i] = math.NaN()
+		}
+		return ret
+	}
+
+	sorted := make([]float64, len(m.samples))
+	for i, s := range m.samples {
+		sorted[i] = s.value
+	}
+	slices.Sort(sorted)
+
+	for i, q := range m.quantiles {
+		rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+		ret[i] = sorted[max(rank, 0)]
+	}
+	return ret
+}
+
+func (m *metricSummaryItem) writeValue(name string, w io.Writer, f Format) error {
+	for i, v := range m.quantileValues() {
+		writeSampleAt(w, name, m.labels.with("quantile", formatFloat(m.quantiles[i])), formatFloat(v), m.timestamp(), f)
+	}
+	writeSampleAt(w, name+"_sum", m.labels, formatFloat(m.sum), m.timestamp(), f)
+	writeSampleAt(w, name+"_count", m.labels, formatFloat(float64(m.count)), m.timestamp(), f)
+	if m.emitCreated {
+		writeCreated(w, name, m.labels, m.created, f)
+	}
+
+	return nil
+}
+
+func (m *metricSummaryItem) protoMetrics(string) []*dto.Metric {
+	s := &dto.Summary{
+		SampleCount: proto.Uint64(m.count),
+		SampleSum:   proto.Float64(m.sum),
+	}
+	for i, v := range m.quantileValues() {
+		s.Quantile = append(s.Quantile, &dto.Quantile{
+			Quantile: proto.Float64(m.quantiles[i]),
+			Value:    proto.Float64(v),
+		})
+	}
+	if m.emitCreated {
+		s.CreatedTimestamp = timestamppb.New(m.created)
+	}
+	return []*dto.Metric{protoTimestamp(&dto.Metric{
+		Label:   protoLabels(m.labels),
+		Summary: s,
+	}, m.updatedAt, m.emitTimestamp)}
+}
+
+// timestamp returns the time of the samples, or zero if not emitted
+func (m *metricSummaryItem) timestamp() time.Time {
+	if !m.emitTimestamp {
+		return time.Time{}
+	}
+	return m.updatedAt
+}
+
+func (m *metricSummaryItem) valueToString() string {
+	return fmt.Sprintf("count=%d sum=%s", m.count, formatFloat(m.sum))
+}
+
+func (m *metricSummaryItem) expired(now time.Time) (bool, string) {
+	// drop old observations here too, since this is invoked before output.
+	m.prune(now)
+	return m.series.expired(now)
+}
+
+func (m *metricSummaryItem) logAttr() slog.Attr {
+	return slog.Group("metric",
+		m.labels.LogAttr(),
+		slog.Uint64("count", m.count),
+		slog.String("sum", formatFloat(m.sum)),
+	)
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSummaryMetric(t *testing.T) {
+	timeNow = func() time.Time { return testBeforeNow }
+	defer func() { timeNow = time.Now }()
+
+	v := NewSummary("testValue", "testHelp", time.Minute, []float64{0.5, 0.9})
+	for i := 1; i <= 10; i++ {
+		v.Observe(Labels{"l1": "b"}, float64(i))
+	}
+
+	var buf bytes.Buffer
+	err := v.outputMetric(&buf, testNow)
+	if err != nil {
+		t.Errorf("summaryMetric.outputMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue summary
+testValue{l1="b",quantile="0.5"} 5
+testValue{l1="b",quantile="0.9"} 9
+testValue_sum{l1="b"} 55
+testValue_count{l1="b"} 10
+`
+	if got != want {
+		t.Errorf("summaryMetric.outputMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestSummaryMetricWindow(t *testing.T) {
+	timeNow = func() time.Time { return testBeforeNow }
+	defer func() { timeNow = time.Now }()
+
+	v := NewSummary("testValue", "testHelp", time.Minute, []float64{0.5})
+	v.Observe(nil, 100)
+
+	timeNow = func() time.Time { return testNow }
+	v.Observe(nil, 1)
+
+	// first observation slides out of the window, while _sum/_count keep it.
+	var buf bytes.Buffer
+	err := v.outputMetric(&buf, testBeforeNow.Add(time.Minute+time.Millisecond))
+	if err != nil {
+		t.Errorf("summaryMetric.outputMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue summary
+testValue{quantile="0.5"} 1
+testValue_sum 101
+testValue_count 2
+`
+	if got != want {
+		t.Errorf("summaryMetric.outputMetric() failed: got:%q want:%q", got, want)
+	}
+
+	// all observations slide out of the window.
+	buf.Reset()
+	v.outputMetric(&buf, testNow.Add(time.Hour))
+	want = `# HELP testValue testHelp
+# TYPE testValue summary
+testValue{quantile="0.5"} NaN
+testValue_sum 101
+testValue_count 2
+`
+	if got := buf.String(); got != want {
+		t.Errorf("summaryMetric.outputMetric() failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestSummaryConcurrentObserve(t *testing.T) {
+	v := NewSummary("testValue", "testHelp", 0, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v.Observe(Labels{"l1": "b"}, float64(j))
+				var buf bytes.Buffer
+				v.outputMetric(&buf, time.Now())
+			}
+		}()
+	}
+	wg.Wait()
+
+	var buf bytes.Buffer
+	v.outputMetric(&buf, time.Now())
+	if !bytes.Contains(buf.Bytes(), []byte("testValue_count{l1=\"b\"} 800\n")) {
+		t.Errorf("summaryMetric concurrent Observe failed: got:%q", buf.String())
+	}
+}
+
+func TestSummaryMetricCreated(t *testing.T) {
+	timeNow = func() time.Time { return testBeforeNow }
+	defer func() { timeNow = time.Now }()
+
+	v := NewSummary("testValue", "testHelp", time.Minute, []float64{0.5}, WithCreated(), WithTimestamp())
+	v.Observe(nil, 2)
+
+	var buf bytes.Buffer
+	if err := v.writeMetric(&buf, testNow, FormatOpenMetrics); err != nil {
+		t.Errorf("summaryMetric.writeMetric() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue summary
+testValue{quantile="0.5"} 2 971176270.000
+testValue_sum 2 971176270.000
+testValue_count 1 971176270.000
+testValue_created 971176270.000
+`
+	if got != want {
+		t.Errorf("summaryMetric.writeMetric() failed: got:%q want:%q", got, want)
+	}
+}
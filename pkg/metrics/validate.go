@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// IsValidMetricName reports whether name matches [a-zA-Z_:][a-zA-Z0-9_:]*
+func IsValidMetricName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || c == ':':
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidLabelName reports whether name matches [a-zA-Z_][a-zA-Z0-9_]*
+// and is not reserved by the `__` prefix.
+func IsValidLabelName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "__") {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_':
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// mustValidMetricName panics if name is not a valid metric name
+func mustValidMetricName(name string) {
+	if !IsValidMetricName(name) {
+		panic(fmt.Sprintf("invalid metric name: %q", name))
+	}
+}
+
+// validateLabels returns an error if labels have an invalid or a reserved name
+func validateLabels(labels Labels, reserved ...string) error {
+	for k := range labels {
+		if !IsValidLabelName(k) {
+			return fmt.Errorf("invalid label name: %q", k)
+		}
+		if slices.Contains(reserved, k) {
+			return fmt.Errorf("reserved label name: %q", k)
+		}
+	}
+	return nil
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes a label value for the exposition. Other characters are kept as is.
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
+
+// validUTF8 replaces invalid UTF-8 sequences of s
+func validUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	return strings.ToValidUTF8(s, string(utf8.RuneError))
+}
+
+// sanitizeLabels returns labels whose values are valid UTF-8, so that the key of a series
+// is built from the values exposed. labels are not modified.
+func sanitizeLabels(labels Labels) Labels {
+	var ret Labels
+	for k, v := range labels {
+		if utf8.ValidString(v) {
+			continue
+		}
+		if ret == nil {
+			ret = maps.Clone(labels)
+		}
+		ret[k] = validUTF8(v)
+	}
+	if ret == nil {
+		return labels
+	}
+	return ret
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var helpOpenMetricsReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeHelp escapes a HELP text. OpenMetrics also escapes double quotes.
+func escapeHelp(help string, f Format) string {
+	help = validUTF8(help)
+	if f == FormatOpenMetrics {
+		return helpOpenMetricsReplacer.Replace(help)
+	}
+	return helpReplacer.Replace(help)
+}
@@ -1,18 +1,123 @@
 package metrics
 
 import (
+	"io"
 	"math"
 	"strconv"
+	"time"
 )
 
-// RoundFloat64 is a stringer float64 with precision round
+// Value is a sample value. String() is exposed as is in the text formats.
+type Value interface {
+	String() string
+	// Float64 returns the value as exposed, e.g. after rounding
+	Float64() float64
+}
+
+// RoundFloat64 is a stringer float64 with precision round.
+// Negative Precision means no rounding, formatted in the shortest representation.
 type RoundFloat64 struct {
 	Value     float64
 	Precision int
 }
 
-func (v RoundFloat64) String() string {
+func (v RoundFloat64) Float64() float64 {
+	if v.Precision < 0 || isSpecialFloat(v.Value) {
+		return v.Value
+	}
 	shift := math.Pow10(v.Precision)
-	round := math.Round(v.Value*shift) / shift
+	return math.Round(v.Value*shift) / shift
+}
+
+func (v RoundFloat64) String() string {
+	round := v.Float64()
+	if v.Precision < 0 || isSpecialFloat(round) {
+		return formatFloat(round)
+	}
 	return strconv.FormatFloat(round, 'f', v.Precision, 64)
 }
+
+// SignificantFloat64 is a stringer float64 rounded to significant digits
+type SignificantFloat64 struct {
+	Value  float64
+	Digits int
+}
+
+func (v SignificantFloat64) Float64() float64 {
+	if v.Digits <= 0 || isSpecialFloat(v.Value) {
+		return v.Value
+	}
+	round, _ := strconv.ParseFloat(strconv.FormatFloat(v.Value, 'e', v.Digits-1, 64), 64)
+	return round
+}
+
+func (v SignificantFloat64) String() string {
+	return formatFloat(v.Float64())
+}
+
+// Int64 is an integer value
+type Int64 int64
+
+func (v Int64) Float64() float64 {
+	return float64(v)
+}
+
+func (v Int64) String() string {
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// Bool is a boolean value exposed as 1 or 0
+type Bool bool
+
+func (v Bool) Float64() float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func (v Bool) String() string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+// isSpecialFloat reports whether v is NaN or infinity, which are not rounded
+func isSpecialFloat(v float64) bool {
+	return math.IsNaN(v) || math.IsInf(v, 0)
+}
+
+// formatFloat formats a float64 with the shortest representation.
+// NaN and infinities are formatted as `NaN`, `+Inf` and `-Inf` of the exposition formats.
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	if math.IsInf(v, -1) {
+		return "-Inf"
+	}
+	if math.IsNaN(v) {
+		return "NaN"
+	}
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatTimestamp formats a time as unix seconds with millisecond precision
+func formatTimestamp(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', 3, 64)
+}
+
+// writeTimestamp writes a sample timestamp following a value.
+// OpenMetrics uses seconds while the Prometheus text format uses milliseconds.
+func writeTimestamp(w io.Writer, t time.Time, f Format) {
+	io.WriteString(w, " ")
+	if f == FormatOpenMetrics {
+		io.WriteString(w, formatTimestamp(t))
+		return
+	}
+	io.WriteString(w, strconv.FormatInt(t.UnixMilli(), 10))
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestValueString(t *testing.T) {
+	for _, tt := range []struct {
+		v    Value
+		want string
+	}{
+		{RoundFloat64{Value: 23.456, Precision: 2}, "23.46"},
+		{RoundFloat64{Value: 23.456, Precision: 0}, "23"},
+		{RoundFloat64{Value: 23.456, Precision: -1}, "23.456"},
+		{RoundFloat64{Value: math.NaN(), Precision: 2}, "NaN"},
+		{RoundFloat64{Value: math.Inf(1), Precision: 2}, "+Inf"},
+		{RoundFloat64{Value: math.Inf(-1), Precision: 2}, "-Inf"},
+		{SignificantFloat64{Value: 1013.256, Digits: 3}, "1010"},
+		{SignificantFloat64{Value: 0.00123456, Digits: 2}, "0.0012"},
+		{SignificantFloat64{Value: -12.345, Digits: 4}, "-12.35"},
+		{SignificantFloat64{Value: math.Inf(-1), Digits: 3}, "-Inf"},
+		{Int64(-42), "-42"},
+		{Bool(true), "1"},
+		{Bool(false), "0"},
+	} {
+		if got := tt.v.String(); got != tt.want {
+			t.Errorf("%#v.String() failed: got:%q want:%q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestPrecisions(t *testing.T) {
+	p := Precisions{}
+	for _, s := range []string{"testValue=1", "testValue2=3s"} {
+		if err := p.Set(s); err != nil {
+			t.Fatalf("Precisions.Set(%q) failed: %v", s, err)
+		}
+	}
+	for _, s := range []string{"testValue", "1test=1", "testValue=a", "testValue=0s"} {
+		if err := p.Set(s); err == nil {
+			t.Errorf("Precisions.Set(%q) should fail", s)
+		}
+	}
+	if got, want := p.String(), "testValue=1,testValue2=3s"; got != want {
+		t.Errorf("Precisions.String() failed: got:%q want:%q", got, want)
+	}
+
+	s := MetricSet{}
+	s.Add(
+		NewGauge("testValue", "testHelp", WithPrecisions(p)),
+		NewGauge("testValue2", "testHelp", WithPrecisions(p)),
+		NewGauge("testValue3", "testHelp", WithPrecisions(p)),
+	)
+	for _, m := range s {
+		m.Set(Labels{"l1": "a"}, RoundFloat64{Value: 1234.5678, Precision: 2})
+		m.Set(Labels{"l1": "b"}, Int64(7))
+	}
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Errorf("MetricSet.Write() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP testValue testHelp
+# TYPE testValue gauge
+testValue{l1="a"} 1234.6
+testValue{l1="b"} 7
+# HELP testValue2 testHelp
+# TYPE testValue2 gauge
+testValue2{l1="a"} 1230
+testValue2{l1="b"} 7
+# HELP testValue3 testHelp
+# TYPE testValue3 gauge
+testValue3{l1="a"} 1234.57
+testValue3{l1="b"} 7
+`
+	if got != want {
+		t.Errorf("precision override failed: got:%q want:%q", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+// Package promadapter bridges homeprobe metrics and prometheus/client_golang.
+package promadapter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+)
+
+// Source is a set of homeprobe metrics, e.g. metrics.MetricSet
+type Source interface {
+	WriteFormat(w io.Writer, f metrics.Format) error
+}
+
+// gatherer exposes a Source as prometheus.Gatherer
+type gatherer struct {
+	src Source
+}
+
+// NewGatherer returns a prometheus.Gatherer of the Source.
+// Expired series are dropped as on the homeprobe /metrics handler.
+func NewGatherer(src Source) prometheus.Gatherer {
+	return &gatherer{src: src}
+}
+
+func (g *gatherer) Gather() ([]*dto.MetricFamily, error) {
+	var buf bytes.Buffer
+	if err := g.src.WriteFormat(&buf, metrics.FormatProtobuf); err != nil {
+		return nil, fmt.Errorf("write metrics: %w", err)
+	}
+
+	r := bufio.NewReader(&buf)
+	var ret []*dto.MetricFamily
+	for {
+		mf := &dto.MetricFamily{}
+		if err := protodelim.UnmarshalFrom(r, mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ret, nil
+			}
+			return nil, fmt.Errorf("read metrics: %w", err)
+		}
+		ret = append(ret, mf)
+	}
+}
+
+// collector exposes a Source as prometheus.Collector
+type collector struct {
+	g prometheus.Gatherer
+}
+
+// NewCollector returns an unchecked prometheus.Collector of the Source,
+// so that homeprobe metrics can be registered to a client_golang registry.
+func NewCollector(src Source) prometheus.Collector {
+	return &collector{g: NewGatherer(src)}
+}
+
+// Describe sends nothing, since the series of the Source vary at runtime.
+func (c *collector) Describe(chan<- *prometheus.Desc) {}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	mfs, err := c.g.Gather()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(
+			prometheus.NewDesc("homeprobe_collector_error", "Error while collecting homeprobe metrics", nil, nil),
+			err,
+		)
+		return
+	}
+
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			labelNames := make([]string, 0, len(m.Label))
+			for _, lp := range m.Label {
+				labelNames = append(labelNames, lp.GetName())
+			}
+			ch <- &protoMetric{
+				desc: prometheus.NewDesc(mf.GetName(), mf.GetHelp(), labelNames, nil),
+				m:    m,
+			}
+		}
+	}
+}
+
+// protoMetric is a prometheus.Metric of a decoded dto.Metric
+type protoMetric struct {
+	desc *prometheus.Desc
+	m    *dto.Metric
+}
+
+func (p *protoMetric) Desc() *prometheus.Desc {
+	return p.desc
+}
+
+func (p *protoMetric) Write(out *dto.Metric) error {
+	proto.Merge(out, p.m)
+	return nil
+}
+
+// Handler returns a /metrics handler exposing the Source together with
+// client_golang gatherers. The exposition format is negotiated by promhttp.
+func Handler(src Source, gs ...prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(
+		append(prometheus.Gatherers{NewGatherer(src)}, gs...),
+		promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		},
+	)
+}
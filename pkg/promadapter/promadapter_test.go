@@ -0,0 +1,73 @@
+package promadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+)
+
+func testMetricSet() (metrics.MetricSet, metrics.Metric) {
+	s := metrics.MetricSet{}
+	temp := metrics.NewGauge("temperature", "Temperature")
+	count := metrics.NewCounter("received", "Received advertisements")
+	s.Add(temp, count)
+
+	temp.Set(metrics.Labels{"place": "inside"}, metrics.RoundFloat64{Value: 23.456, Precision: 2})
+	temp.SetWithTimeout(metrics.Labels{"place": "expired"},
+		metrics.RoundFloat64{Value: 1, Precision: 2}, time.Now().Add(-time.Second))
+	count.Add(metrics.Labels{"place": "inside"}, 3)
+
+	return s, temp
+}
+
+func TestCollector(t *testing.T) {
+	s, _ := testMetricSet()
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(NewCollector(s))
+
+	want := `# HELP received_total Received advertisements
+# TYPE received_total counter
+received_total{place="inside"} 3
+# HELP temperature Temperature
+# TYPE temperature gauge
+temperature{place="inside"} 23.46
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Errorf("NewCollector() failed: %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	s, _ := testMetricSet()
+
+	reg := prometheus.NewRegistry()
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "other_value", Help: "Other value"})
+	other.Set(1)
+	reg.MustRegister(other)
+
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+	Handler(s, reg).ServeHTTP(w, r)
+
+	got := w.Body.String()
+	for _, want := range []string{
+		"other_value 1\n",
+		"received_total{place=\"inside\"} 3\n",
+		"temperature{place=\"inside\"} 23.46\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Handler() failed: %q not in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "expired") {
+		t.Errorf("Handler() exposes expired series: %q", got)
+	}
+}
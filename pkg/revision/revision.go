@@ -21,3 +21,18 @@ func Usage(binname string) func() {
 		flag.PrintDefaults()
 	}
 }
+
+// Commit returns the commit the binary is built from.
+func Commit() string {
+	return commit
+}
+
+// Tag returns the tag the binary is built from.
+func Tag() string {
+	return tag
+}
+
+// GoVersion returns the version of the Go compiler used.
+func GoVersion() string {
+	return runtime.Version()
+}
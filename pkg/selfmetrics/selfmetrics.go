@@ -0,0 +1,104 @@
+// Package selfmetrics exposes metrics of the probe itself, so that a degraded
+// probe can be seen from Prometheus.
+package selfmetrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/revision"
+)
+
+// Metrics is a set of metrics of the probe itself
+type Metrics struct {
+	buildInfo   metrics.Info
+	sensorReads metrics.Counter
+	duration    metrics.Histogram
+	advReceived metrics.Counter
+	advIgnored  metrics.Counter
+	scrapes     metrics.Counter
+}
+
+// New creates self metrics, and sets the build information from pkg/revision.
+func New(opts ...metrics.Option) *Metrics {
+	m := &Metrics{
+		buildInfo:   metrics.NewInfo("homeprobe_build_info", "Build information of the probe", opts...),
+		sensorReads: metrics.NewCounter("homeprobe_sensor_reads_total", "Sensor reads by result", opts...),
+		duration: metrics.NewHistogram("homeprobe_measurement_duration_seconds", "Duration of sensor reads",
+			metrics.DefBuckets, append([]metrics.Option{metrics.WithUnit("seconds")}, opts...)...),
+		advReceived: metrics.NewCounter("homeprobe_advertisements_received_total", "BLE advertisements received", opts...),
+		advIgnored:  metrics.NewCounter("homeprobe_advertisements_ignored_total", "BLE advertisements ignored", opts...),
+		scrapes:     metrics.NewCounter("homeprobe_scrapes_total", "Scrapes by HTTP status code", opts...),
+	}
+
+	m.buildInfo.SetInfo(nil, metrics.Labels{
+		"commit":    revision.Commit(),
+		"tag":       revision.Tag(),
+		"goversion": revision.GoVersion(),
+	})
+
+	return m
+}
+
+// Metrics returns the metrics to be added to a MetricSet or a Registry
+func (m *Metrics) Metrics() []metrics.Metric {
+	return []metrics.Metric{m.buildInfo, m.sensorReads, m.duration, m.advReceived, m.advIgnored, m.scrapes}
+}
+
+// Read reads the sensor by fn, and counts its result and duration.
+// The error of fn is returned as is.
+func (m *Metrics) Read(sensor string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	m.duration.Observe(metrics.Labels{"sensor": sensor}, time.Since(start).Seconds())
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.sensorReads.Inc(metrics.Labels{"sensor": sensor, "result": result})
+
+	return err
+}
+
+// AdvertisementReceived counts an advertisement from the device
+func (m *Metrics) AdvertisementReceived(device string) {
+	m.advReceived.Inc(metrics.Labels{"device": device})
+}
+
+// AdvertisementIgnored counts an advertisement from the device not reflected
+// to metrics, e.g. a duplicated sequence.
+func (m *Metrics) AdvertisementIgnored(device string) {
+	m.advIgnored.Inc(metrics.Labels{"device": device})
+}
+
+// Handler counts scrapes served by next with its status code
+func (m *Metrics) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		m.scrapes.Inc(metrics.Labels{"code": strconv.Itoa(sw.code)})
+	})
+}
+
+// statusWriter records the status code written
+type statusWriter struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+func (s *statusWriter) WriteHeader(code int) {
+	if !s.wroteHeader {
+		s.code = code
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusWriter) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
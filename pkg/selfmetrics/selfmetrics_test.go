@@ -0,0 +1,59 @@
+package selfmetrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+)
+
+func TestSelfMetrics(t *testing.T) {
+	m := New()
+	s := metrics.MetricSet{}
+	s.Add(m.Metrics()...)
+
+	if err := m.Read("sht3x", func() error { return nil }); err != nil {
+		t.Errorf("Read() failed: %v", err)
+	}
+	readErr := errors.New("i2c error")
+	if err := m.Read("sht3x", func() error { return readErr }); err != readErr {
+		t.Errorf("Read() failed: got:%v want:%v", err, readErr)
+	}
+	m.AdvertisementReceived("AA:BB:CC:DD:EE:FF")
+	m.AdvertisementReceived("AA:BB:CC:DD:EE:FF")
+	m.AdvertisementIgnored("AA:BB:CC:DD:EE:FF")
+
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	for _, target := range []string{"/metrics", "/metrics", "/metrics?fail"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Errorf("Write() failed: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		`homeprobe_build_info{commit="no commit",goversion="go`,
+		`homeprobe_sensor_reads_total{result="success",sensor="sht3x"} 1` + "\n",
+		`homeprobe_sensor_reads_total{result="failure",sensor="sht3x"} 1` + "\n",
+		`homeprobe_measurement_duration_seconds_count{sensor="sht3x"} 2` + "\n",
+		`homeprobe_advertisements_received_total{device="AA:BB:CC:DD:EE:FF"} 2` + "\n",
+		`homeprobe_advertisements_ignored_total{device="AA:BB:CC:DD:EE:FF"} 1` + "\n",
+		`homeprobe_scrapes_total{code="200"} 2` + "\n",
+		`homeprobe_scrapes_total{code="500"} 1` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("self metrics failed: %q not in %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package units
+
+// baseSuffixes are the unit suffixes of the base units in the standard names
+var baseSuffixes = map[Quantity]string{
+	Temperature:      "celsius",
+	Pressure:         "hectopascals",
+	AbsoluteHumidity: "grams_per_cubic_meter",
+}
+
+// quantityNames maps the legacy names of quantities to the standard names without unit suffix
+var quantityNames = map[string]struct {
+	name     string
+	quantity Quantity
+}{
+	"temperature":          {"homeprobe_temperature", Temperature},
+	"pressure":             {"homeprobe_pressure", Pressure},
+	"absolute_humidity":    {"homeprobe_absolute_humidity", AbsoluteHumidity},
+	"dew_point":            {"homeprobe_dew_point", Temperature},
+	"heat_index":           {"homeprobe_heat_index", Temperature},
+	"apparent_temperature": {"homeprobe_apparent_temperature", Temperature},
+	"wet_bulb":             {"homeprobe_wet_bulb", Temperature},
+	"vapor_pressure":       {"homeprobe_vapor_pressure", Pressure},
+	"pressure_tendency":    {"homeprobe_pressure_tendency", Pressure},
+	"station_pressure":     {"homeprobe_station_pressure", Pressure},
+}
+
+// StandardNames maps the legacy metric names to the standard names following
+// the Prometheus naming conventions. It is used with metrics.WithNaming.
+var StandardNames = map[string]string{
+	"relative_humidity":          "homeprobe_relative_humidity_percent",
+	"disconfort_index":           "homeprobe_discomfort_index",
+	"humidex":                    "homeprobe_humidex",
+	"wbgt_estimated":             "homeprobe_wbgt_estimated_celsius",
+	"heat_stroke_level":          "homeprobe_heat_stroke_level",
+	"mixing_ratio":               "homeprobe_mixing_ratio_grams_per_kilogram",
+	"specific_humidity":          "homeprobe_specific_humidity_grams_per_kilogram",
+	"enthalpy":                   "homeprobe_enthalpy_kilojoules_per_kilogram",
+	"vapor_pressure_deficit_kpa": "homeprobe_vapor_pressure_deficit_kilopascals",
+	"vpd_stage":                  "homeprobe_vpd_stage",
+	"pressure_characteristic":    "homeprobe_pressure_characteristic",
+	"pressure_trend":             "homeprobe_pressure_trend",
+	"zambretti_forecast_number":  "homeprobe_zambretti_forecast_number",
+	"zambretti_forecast":         "homeprobe_zambretti_forecast",
+	"mold_index":                 "homeprobe_mold_index",
+	"co2":                        "homeprobe_co2_ppm",
+	"eco2":                       "homeprobe_eco2_ppm",
+	"voc":                        "homeprobe_tvoc_ppb",
+	"ambient_light":              "homeprobe_illuminance_lux",
+	"uv_index":                   "homeprobe_uv_index",
+	"sound_noise":                "homeprobe_sound_level_decibels",
+	"heat_stroke":                "homeprobe_wbgt_celsius",
+	"sensor_vbat":                "homeprobe_sensor_battery_volts",
+	"sensor":                     "homeprobe_sensor",
+}
+
+func init() {
+	// names of all the units of quantities, e.g. temperature_fahrenheit
+	for legacy, std := range quantityNames {
+		for _, u := range units[std.quantity] {
+			if u.Suffix == "" {
+				StandardNames[legacy] = std.name + "_" + baseSuffixes[std.quantity]
+			} else {
+				StandardNames[legacy+"_"+u.Suffix] = std.name + "_" + u.Suffix
+			}
+		}
+	}
+}
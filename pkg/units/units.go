@@ -0,0 +1,189 @@
+// Package units emits values converted to the units selected per binary,
+// on top of the conversions of pkg/weather.
+package units
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+	"github.com/walkure/homeprobe/pkg/weather"
+)
+
+// Quantity is a physical quantity whose values are set in its base unit
+type Quantity int
+
+const (
+	// Temperature in celsius, including dew point
+	Temperature Quantity = iota
+	// Pressure in hectopascals
+	Pressure
+	// AbsoluteHumidity in g/m^3
+	AbsoluteHumidity
+)
+
+// Unit is an output unit of a quantity
+type Unit struct {
+	// Name is used to select the unit by flags
+	Name string
+	// Suffix is appended to the metric name. The base unit has no suffix,
+	// so that the existing series are kept as is.
+	Suffix string
+	// Symbol is appended to the help
+	Symbol  string
+	Convert func(float64) float64
+	// Precision is added to the decimal places of the value set in the base unit,
+	// so that the unit keeps about the same resolution as the base.
+	Precision int
+}
+
+func identity(v float64) float64 {
+	return v
+}
+
+// units of each quantity. the first is the base unit.
+var units = map[Quantity][]Unit{
+	Temperature: {
+		{Name: "celsius", Symbol: "°C", Convert: identity},
+		{Name: "fahrenheit", Suffix: "fahrenheit", Symbol: "°F", Convert: weather.CelsiusToFahrenheit},
+		{Name: "kelvin", Suffix: "kelvin", Symbol: "K", Convert: weather.CelsiusToKelvin},
+	},
+	Pressure: {
+		{Name: "hpa", Symbol: "hPa", Convert: identity},
+		{Name: "pa", Suffix: "pascals", Symbol: "Pa", Convert: weather.HectopascalsToPascals, Precision: -2},
+		{Name: "kpa", Suffix: "kilopascals", Symbol: "kPa", Convert: weather.HectopascalsToKilopascals, Precision: 1},
+		{Name: "inhg", Suffix: "inches_of_mercury", Symbol: "inHg", Convert: weather.HectopascalsToInchesOfMercury, Precision: 2},
+		{Name: "mmhg", Suffix: "millimeters_of_mercury", Symbol: "mmHg", Convert: weather.HectopascalsToMillimetersOfMercury, Precision: 1},
+	},
+	AbsoluteHumidity: {
+		{Name: "gm3", Symbol: "g/m^3", Convert: identity},
+		{Name: "grft3", Suffix: "grains_per_cubic_foot", Symbol: "gr/ft^3", Convert: weather.GramsPerCubicMeterToGrainsPerCubicFoot, Precision: 1},
+	},
+}
+
+// Selection is the units to emit for each quantity
+type Selection map[Quantity][]Unit
+
+// DefaultSelection emits the base units only
+func DefaultSelection() Selection {
+	s := Selection{}
+	for q, u := range units {
+		s[q] = u[:1]
+	}
+	return s
+}
+
+// selectionFlag parses comma separated unit names of the quantity
+type selectionFlag struct {
+	s Selection
+	q Quantity
+}
+
+func (f selectionFlag) String() string {
+	if f.s == nil {
+		return ""
+	}
+	var names []string
+	for _, u := range f.s[f.q] {
+		names = append(names, u.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (f selectionFlag) Set(v string) error {
+	var selected []Unit
+	for _, name := range strings.Split(v, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		i := slices.IndexFunc(units[f.q], func(u Unit) bool { return u.Name == name })
+		if i < 0 {
+			return fmt.Errorf("unknown unit: %q", name)
+		}
+		if !slices.ContainsFunc(selected, func(u Unit) bool { return u.Name == name }) {
+			selected = append(selected, units[f.q][i])
+		}
+	}
+	f.s[f.q] = selected
+	return nil
+}
+
+func unitNames(q Quantity) string {
+	var names []string
+	for _, u := range units[q] {
+		names = append(names, u.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// RegisterFlags registers flags `temperature_unit`, `pressure_unit` and `humidity_unit` to fs,
+// and returns the selection configured by them.
+func RegisterFlags(fs *flag.FlagSet) Selection {
+	s := DefaultSelection()
+	fs.Var(selectionFlag{s, Temperature}, "temperature_unit", "Comma separated units of temperature: "+unitNames(Temperature))
+	fs.Var(selectionFlag{s, Pressure}, "pressure_unit", "Comma separated units of pressure: "+unitNames(Pressure))
+	fs.Var(selectionFlag{s, AbsoluteHumidity}, "humidity_unit", "Comma separated units of absolute humidity: "+unitNames(AbsoluteHumidity))
+	return s
+}
+
+// Gauge sets a value of the quantity to the gauges of the selected units
+type Gauge struct {
+	gauges []metrics.Metric
+	units  []Unit
+}
+
+// NewGauge creates gauges of the selected units of the quantity.
+// The gauge of a unit other than the base is named `<name>_<suffix>`, with the suffix as its unit.
+func (s Selection) NewGauge(name, help string, q Quantity, opts ...metrics.Option) *Gauge {
+	selected := s[q]
+	if len(selected) == 0 {
+		selected = units[q][:1]
+	}
+
+	g := &Gauge{}
+	for _, u := range selected {
+		gaugeName, gaugeHelp, unit := name, help, u.Suffix
+		if u.Suffix == "" {
+			// exposed in OpenMetrics once renamed to the standard name ending with the unit
+			if _, ok := quantityNames[name]; ok {
+				unit = baseSuffixes[q]
+			}
+		} else {
+			gaugeName += "_" + u.Suffix
+			gaugeHelp += " " + u.Symbol
+		}
+		gaugeOpts := opts
+		if unit != "" {
+			gaugeOpts = append(slices.Clip(opts), metrics.WithUnit(unit))
+		}
+		g.gauges = append(g.gauges, metrics.NewGauge(gaugeName, gaugeHelp, gaugeOpts...))
+		g.units = append(g.units, u)
+	}
+	return g
+}
+
+// Metrics returns a gauge per selected unit, in the order selected
+func (g *Gauge) Metrics() []metrics.Metric {
+	return g.gauges
+}
+
+// Set sets value in the base unit
+func (g *Gauge) Set(labels metrics.Labels, value metrics.RoundFloat64) {
+	g.SetWithTimeout(labels, value, time.Time{})
+}
+
+// SetWithTimeout sets value in the base unit. Each unit is rounded by value.Precision
+// with its own precision added, but a negative value.Precision keeps values as is.
+func (g *Gauge) SetWithTimeout(labels metrics.Labels, value metrics.RoundFloat64, expireAt time.Time) {
+	for i, m := range g.gauges {
+		precision := value.Precision
+		if precision >= 0 {
+			precision = max(precision+g.units[i].Precision, 0)
+		}
+		m.SetWithTimeout(labels, metrics.RoundFloat64{
+			Value:     g.units[i].Convert(value.Value),
+			Precision: precision,
+		}, expireAt)
+	}
+}
@@ -0,0 +1,162 @@
+package units
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/walkure/homeprobe/pkg/metrics"
+)
+
+func TestSelectionFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	s := RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"--temperature_unit", "celsius,Fahrenheit", "--pressure_unit", "inhg"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	for q, want := range map[Quantity]string{
+		Temperature:      "celsius,fahrenheit",
+		Pressure:         "inhg",
+		AbsoluteHumidity: "gm3",
+	} {
+		if got := (selectionFlag{s, q}).String(); got != want {
+			t.Errorf("selection of %d failed: got:%q want:%q", q, got, want)
+		}
+	}
+
+	if err := fs.Parse([]string{"--pressure_unit", "bar"}); err == nil {
+		t.Errorf("Parse() of unknown unit should fail")
+	}
+}
+
+func TestGauge(t *testing.T) {
+	s := DefaultSelection()
+	if err := (selectionFlag{s, Temperature}).Set("celsius,fahrenheit,kelvin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := (selectionFlag{s, Pressure}).Set("pa,kpa,inhg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := (selectionFlag{s, AbsoluteHumidity}).Set("grft3"); err != nil {
+		t.Fatal(err)
+	}
+
+	temp := s.NewGauge("temperature", "Temperature", Temperature)
+	pressure := s.NewGauge("pressure", "Pressure", Pressure)
+	absHumid := s.NewGauge("absolute_humidity", "Absolute Humidity", AbsoluteHumidity)
+
+	set := metrics.MetricSet{}
+	for _, g := range []*Gauge{temp, pressure, absHumid} {
+		set.Add(g.Metrics()...)
+	}
+
+	labels := metrics.Labels{"place": "inside"}
+	temp.Set(labels, metrics.RoundFloat64{Value: 25, Precision: 2})
+	pressure.Set(labels, metrics.RoundFloat64{Value: 1013.25, Precision: 2})
+	absHumid.Set(labels, metrics.RoundFloat64{Value: 10, Precision: 2})
+
+	var buf bytes.Buffer
+	if err := set.Write(&buf); err != nil {
+		t.Errorf("Write() failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `# HELP absolute_humidity_grains_per_cubic_foot Absolute Humidity gr/ft^3
+# TYPE absolute_humidity_grains_per_cubic_foot gauge
+absolute_humidity_grains_per_cubic_foot{place="inside"} 4.370
+# HELP pressure_inches_of_mercury Pressure inHg
+# TYPE pressure_inches_of_mercury gauge
+pressure_inches_of_mercury{place="inside"} 29.9213
+# HELP pressure_kilopascals Pressure kPa
+# TYPE pressure_kilopascals gauge
+pressure_kilopascals{place="inside"} 101.325
+# HELP pressure_pascals Pressure Pa
+# TYPE pressure_pascals gauge
+pressure_pascals{place="inside"} 101325
+# HELP temperature Temperature
+# TYPE temperature gauge
+temperature{place="inside"} 25.00
+# HELP temperature_fahrenheit Temperature °F
+# TYPE temperature_fahrenheit gauge
+temperature_fahrenheit{place="inside"} 77.00
+# HELP temperature_kelvin Temperature K
+# TYPE temperature_kelvin gauge
+temperature_kelvin{place="inside"} 298.15
+`
+	if got != want {
+		t.Errorf("converted gauges failed: got:%q want:%q", got, want)
+	}
+}
+
+func TestStandardNames(t *testing.T) {
+	for legacy, want := range map[string]string{
+		"temperature":                             "homeprobe_temperature_celsius",
+		"temperature_fahrenheit":                  "homeprobe_temperature_fahrenheit",
+		"pressure":                                "homeprobe_pressure_hectopascals",
+		"pressure_inches_of_mercury":              "homeprobe_pressure_inches_of_mercury",
+		"absolute_humidity":                       "homeprobe_absolute_humidity_grams_per_cubic_meter",
+		"absolute_humidity_grains_per_cubic_foot": "homeprobe_absolute_humidity_grains_per_cubic_foot",
+		"disconfort_index":                        "homeprobe_discomfort_index",
+		"heat_index_fahrenheit":                   "homeprobe_heat_index_fahrenheit",
+		"humidex":                                 "homeprobe_humidex",
+		"wbgt_estimated":                          "homeprobe_wbgt_estimated_celsius",
+	} {
+		if got := StandardNames[legacy]; got != want {
+			t.Errorf("StandardNames[%q] failed: got:%q want:%q", legacy, got, want)
+		}
+	}
+	for legacy, std := range StandardNames {
+		if !metrics.IsValidMetricName(std) {
+			t.Errorf("StandardNames[%q] invalid: %q", legacy, std)
+		}
+	}
+}
+
+func TestGaugeUnit(t *testing.T) {
+	s := DefaultSelection()
+	if err := (selectionFlag{s, Temperature}).Set("celsius,fahrenheit"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		naming metrics.Naming
+		want   string
+	}{
+		// legacy names do not end with the unit
+		{metrics.NamingLegacy, `# HELP temperature Temperature
+# TYPE temperature gauge
+temperature 25.00
+# HELP temperature_fahrenheit Temperature °F
+# TYPE temperature_fahrenheit gauge
+# UNIT temperature_fahrenheit fahrenheit
+temperature_fahrenheit 77.00
+# EOF
+`},
+		{metrics.NamingStandard, `# HELP homeprobe_temperature_celsius Temperature
+# TYPE homeprobe_temperature_celsius gauge
+# UNIT homeprobe_temperature_celsius celsius
+homeprobe_temperature_celsius 25.00
+# HELP homeprobe_temperature_fahrenheit Temperature °F
+# TYPE homeprobe_temperature_fahrenheit gauge
+# UNIT homeprobe_temperature_fahrenheit fahrenheit
+homeprobe_temperature_fahrenheit 77.00
+# EOF
+`},
+	} {
+		temp := s.NewGauge("temperature", "Temperature", Temperature, metrics.WithNaming(tt.naming, StandardNames))
+		set := metrics.MetricSet{}
+		set.Add(temp.Metrics()...)
+		temp.Set(nil, metrics.RoundFloat64{Value: 25, Precision: 2})
+
+		var buf bytes.Buffer
+		if err := set.WriteFormat(&buf, metrics.FormatOpenMetrics); err != nil {
+			t.Errorf("WriteFormat() failed: %v", err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("unit of naming %s failed: got:%q want:%q", tt.naming, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package weather
+
+import (
+	"math"
+)
+
+// HeatIndex returns the NOAA heat index in celsius, by the Rothfusz regression
+// with the adjustments of the US National Weather Service.
+// The simple formula of Steadman is used below 80°F.
+func HeatIndex(temp, relativeHumid float64) float64 {
+	t := CelsiusToFahrenheit(temp)
+	rh := relativeHumid
+
+	hi := 0.5 * (t + 61.0 + (t-68.0)*1.2 + rh*0.094)
+	if (hi+t)/2 >= 80 {
+		hi = -42.379 + 2.04901523*t + 10.14333127*rh -
+			0.22475541*t*rh - 0.00683783*t*t - 0.05481717*rh*rh +
+			0.00122874*t*t*rh + 0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+		if rh < 13 && t >= 80 && t <= 112 {
+			hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+		} else if rh > 85 && t >= 80 && t <= 87 {
+			hi += (rh - 85) / 10 * (87 - t) / 5
+		}
+	}
+
+	return (hi - 32) * 5 / 9
+}
+
+// Humidex returns the humidex of Environment Canada
+func Humidex(temp, relativeHumid float64) float64 {
+	// by the dew point over water, also where DewPoint returns the frost point
+	dewPoint := magnusInverse(VaporPressure(temp, relativeHumid), magnusWaterA, magnusWaterB)
+	return HumidexFromDewPoint(temp, dewPoint)
+}
+
+// HumidexFromDewPoint returns the humidex of Environment Canada by dew point
+func HumidexFromDewPoint(temp, dewPoint float64) float64 {
+	vaporPressure := 6.11 * math.Exp(5417.7530*(1/273.16-1/(dewPoint+273.15)))
+	return temp + 0.5555*(vaporPressure-10)
+}
+
+// ApparentTemperature returns the Steadman apparent temperature in celsius
+// without radiation, as used by the Australian Bureau of Meteorology.
+// windSpeed is in m/s at 10m height, 0 for indoors.
+func ApparentTemperature(temp, relativeHumid, windSpeed float64) float64 {
+	vaporPressure := relativeHumid / 100 * 6.105 * math.Exp(17.27*temp/(237.7+temp))
+	return temp + 0.33*vaporPressure - 0.70*windSpeed - 4.00
+}
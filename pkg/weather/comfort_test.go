@@ -0,0 +1,81 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHeatIndex(t *testing.T) {
+	// NWS heat index chart in fahrenheit
+	for _, tt := range []struct {
+		temp, humid float64
+		want        float64
+	}{
+		// simple formula
+		{80, 40, 80},
+		{80, 80, 84},
+		// Rothfusz regression
+		{88, 60, 95},
+		{90, 70, 106},
+		{94, 55, 106},
+		{100, 50, 118},
+		{104, 40, 119},
+		// adjustment for high humidity
+		{86, 90, 105},
+	} {
+		got := CelsiusToFahrenheit(HeatIndex((tt.temp-32)*5/9, tt.humid))
+		if math.Abs(got-tt.want) > 1 {
+			t.Errorf("HeatIndex(%v°F, %v) failed: got:%v°F want:%v°F", tt.temp, tt.humid, got, tt.want)
+		}
+	}
+}
+
+func TestHumidex(t *testing.T) {
+	// examples of Environment Canada by dew point
+	for _, tt := range []struct {
+		temp, dewPoint float64
+		want           float64
+	}{
+		{30, 15, 34},
+		{30, 25, 42},
+	} {
+		got := HumidexFromDewPoint(tt.temp, tt.dewPoint)
+		if math.Abs(got-tt.want) > 0.5 {
+			t.Errorf("HumidexFromDewPoint(%v, %v) failed: got:%v want:%v", tt.temp, tt.dewPoint, got, tt.want)
+		}
+	}
+
+	// Environment Canada humidex table
+	for _, tt := range []struct {
+		temp, humid float64
+		want        float64
+	}{
+		{30, 50, 37},
+		{30, 70, 41},
+		{35, 50, 45},
+		{25, 80, 33},
+	} {
+		got := Humidex(tt.temp, tt.humid)
+		if math.Abs(got-tt.want) > 1 {
+			t.Errorf("Humidex(%v, %v) failed: got:%v want:%v", tt.temp, tt.humid, got, tt.want)
+		}
+	}
+}
+
+func TestApparentTemperature(t *testing.T) {
+	// worked by the formula of Australian Bureau of Meteorology, without radiation
+	for _, tt := range []struct {
+		temp, humid, wind float64
+		want              float64
+	}{
+		{30, 50, 0, 33.0},
+		{20, 60, 0, 20.6},
+		{35, 20, 5, 31.2},
+		{10, 80, 2, 7.8},
+	} {
+		got := ApparentTemperature(tt.temp, tt.humid, tt.wind)
+		if math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("ApparentTemperature(%v, %v, %v) failed: got:%v want:%v", tt.temp, tt.humid, tt.wind, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package weather
+
+import (
+	"math"
+)
+
+// Magnus coefficients over water and over ice (Sonntag 1990)
+const (
+	magnusWaterA = 17.62
+	magnusWaterB = 243.12
+	magnusIceA   = 22.46
+	magnusIceB   = 272.62
+	// saturation vapor pressure at 0°C in hectopascals
+	magnusE0 = 6.112
+)
+
+// DewPoint returns the dew point by the Magnus formula, or the frost point if the vapor
+// deposits as frost, i.e. below 0°C. It returns NaN if relativeHumid is not positive.
+// relativeHumid is over water, as the sensors report also below 0°C.
+func DewPoint(temp, relativeHumid float64) float64 {
+	if relativeHumid <= 0 {
+		return math.NaN()
+	}
+
+	e := VaporPressure(temp, relativeHumid)
+	if e < magnusE0 {
+		return magnusInverse(e, magnusIceA, magnusIceB)
+	}
+	return magnusInverse(e, magnusWaterA, magnusWaterB)
+}
+
+// magnusInverse returns the temperature where the vapor pressure in hectopascals saturates
+func magnusInverse(e, a, b float64) float64 {
+	gamma := math.Log(e / magnusE0)
+	return b * gamma / (a - gamma)
+}
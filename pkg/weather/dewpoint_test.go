@@ -0,0 +1,41 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDewPoint(t *testing.T) {
+	for _, tt := range []struct {
+		temp, humid float64
+		want        float64
+	}{
+		// dew point tables over water
+		{20, 50, 9.3},
+		{25, 60, 16.7},
+		{30, 80, 26.2},
+		{10, 100, 10.0},
+		// frost point by the WMO tables of saturation vapor pressure over water and ice
+		{0, 50, -8.2},
+		{-5, 100, -4.4},
+		{-10, 80, -11.4},
+		{-20, 50, -25.1},
+	} {
+		got := DewPoint(tt.temp, tt.humid)
+		if math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("DewPoint(%v, %v) failed: got:%v want:%v", tt.temp, tt.humid, got, tt.want)
+		}
+	}
+
+	// continuous where the frost point meets the dew point
+	if below, above := DewPoint(-0.01, 50), DewPoint(0.01, 50); math.Abs(below-above) > 0.1 {
+		t.Errorf("DewPoint around 0°C failed: got:%v and %v", below, above)
+	}
+	if below, above := DewPoint(10, 49.9), DewPoint(10, 50.1); math.Abs(below-above) > 0.1 {
+		t.Errorf("DewPoint around frost point 0°C failed: got:%v and %v", below, above)
+	}
+
+	if got := DewPoint(20, 0); !math.IsNaN(got) {
+		t.Errorf("DewPoint(20, 0) failed: got:%v want:NaN", got)
+	}
+}
@@ -0,0 +1,47 @@
+package weather
+
+// IndoorWBGT estimates WBGT in celsius from temperature and relative humidity,
+// by the regression of the Ministry of the Environment of Japan (Ono and Tonouchi 2014)
+// without solar radiation and wind, as used for indoors.
+func IndoorWBGT(temp, relativeHumid float64) float64 {
+	return 0.735*temp + 0.0374*relativeHumid + 0.00292*temp*relativeHumid - 4.064
+}
+
+// HeatStrokeLevel is a level of heat stroke risk in daily life by WBGT, defined by
+// the guideline of the Japanese Society of Biometeorology and used by the Ministry of the Environment.
+type HeatStrokeLevel int
+
+const (
+	// HeatStrokeCaution is 注意, WBGT below 25
+	HeatStrokeCaution HeatStrokeLevel = iota
+	// HeatStrokeWarning is 警戒, WBGT 25 to 28
+	HeatStrokeWarning
+	// HeatStrokeSevereWarning is 厳重警戒, WBGT 28 to 31
+	HeatStrokeSevereWarning
+	// HeatStrokeDanger is 危険, WBGT 31 or above
+	HeatStrokeDanger
+)
+
+// HeatStrokeLevelNames are the names of the levels in order
+var HeatStrokeLevelNames = []string{"caution", "warning", "severe_warning", "danger"}
+
+func (l HeatStrokeLevel) String() string {
+	if l < 0 || int(l) >= len(HeatStrokeLevelNames) {
+		return "unknown"
+	}
+	return HeatStrokeLevelNames[l]
+}
+
+// HeatStrokeLevelOf classifies WBGT in celsius
+func HeatStrokeLevelOf(wbgt float64) HeatStrokeLevel {
+	switch {
+	case wbgt >= 31:
+		return HeatStrokeDanger
+	case wbgt >= 28:
+		return HeatStrokeSevereWarning
+	case wbgt >= 25:
+		return HeatStrokeWarning
+	default:
+		return HeatStrokeCaution
+	}
+}
@@ -0,0 +1,48 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIndoorWBGT(t *testing.T) {
+	for _, tt := range []struct {
+		temp, humid float64
+		want        float64
+	}{
+		{25, 50, 19.8},
+		{28, 70, 24.9},
+		{30, 60, 25.5},
+		{32, 80, 29.9},
+		{35, 50, 28.6},
+	} {
+		got := IndoorWBGT(tt.temp, tt.humid)
+		if math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("IndoorWBGT(%v, %v) failed: got:%v want:%v", tt.temp, tt.humid, got, tt.want)
+		}
+	}
+}
+
+func TestHeatStrokeLevel(t *testing.T) {
+	for _, tt := range []struct {
+		wbgt float64
+		want HeatStrokeLevel
+	}{
+		{20, HeatStrokeCaution},
+		{24.9, HeatStrokeCaution},
+		{25, HeatStrokeWarning},
+		{27.9, HeatStrokeWarning},
+		{28, HeatStrokeSevereWarning},
+		{30.9, HeatStrokeSevereWarning},
+		{31, HeatStrokeDanger},
+		{35, HeatStrokeDanger},
+	} {
+		if got := HeatStrokeLevelOf(tt.wbgt); got != tt.want {
+			t.Errorf("HeatStrokeLevelOf(%v) failed: got:%v want:%v", tt.wbgt, got, tt.want)
+		}
+	}
+
+	if got, want := HeatStrokeSevereWarning.String(), "severe_warning"; got != want {
+		t.Errorf("String() failed: got:%q want:%q", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package weather
+
+import (
+	"math"
+	"time"
+)
+
+// parameters of the VTT mold growth model for pine sapwood with resawn surface,
+// the most sensitive material of Hukka and Viitanen (1999)
+const (
+	moldWoodSpecies    = 0 // 0: pine, 1: spruce
+	moldSurfaceQuality = 0 // 0: resawn, 1: kiln dried
+	moldIndexMax       = 6
+	// longest step of integration. Longer gaps of samples are skipped, not extrapolated.
+	moldMaxStep = time.Hour
+)
+
+// MoldCriticalHumidity returns the lowest relative humidity where mold grows at the temperature
+func MoldCriticalHumidity(temp float64) float64 {
+	if temp > 20 {
+		return 80
+	}
+	return -0.00267*math.Pow(temp, 3) + 0.160*temp*temp - 3.13*temp + 100
+}
+
+// MoldState is the state of the VTT mold growth model (Hukka and Viitanen 1999) at a location.
+// The zero value is the state without mold.
+type MoldState struct {
+	// Index is the mold index from 0 (no growth) to 6 (heavy growth)
+	Index float64 `json:"index"`
+	// DrySince is the start of the conditions unfavorable to mold, zero while favorable
+	DrySince time.Time `json:"dry_since"`
+	// UpdatedAt is the time of the last sample
+	UpdatedAt time.Time `json:"updated_at"`
+	// Skipped is the total of the gaps of samples not integrated
+	Skipped time.Duration `json:"skipped"`
+}
+
+// Update integrates the model from the last sample up to the time by temperature
+// and relative humidity. The first sample only starts the model.
+// A gap longer than an hour is skipped, since the conditions during it are unknown:
+// the index neither grows nor declines over it, the gap is added to Skipped, and the model
+// restarts from the sample as if the conditions had just changed.
+func (s *MoldState) Update(at time.Time, temp, relativeHumid float64) {
+	last := s.UpdatedAt
+	if !at.After(last) {
+		return
+	}
+	s.UpdatedAt = at
+	if last.IsZero() {
+		return
+	}
+	step := at.Sub(last)
+	if step > moldMaxStep {
+		s.Skipped += step
+		s.DrySince = time.Time{}
+		return
+	}
+	hours := step.Hours()
+
+	critical := MoldCriticalHumidity(temp)
+	if temp <= 0 || temp >= 50 || relativeHumid < critical {
+		if s.DrySince.IsZero() {
+			s.DrySince = at.Add(-step)
+		}
+		s.Index = max(s.Index-moldDeclineRate(at.Sub(s.DrySince))*hours, 0)
+		return
+	}
+	s.DrySince = time.Time{}
+
+	lnT, lnRH := math.Log(temp), math.Log(relativeHumid)
+	// weeks to reach index 1 and 3
+	tm := math.Exp(-0.68*lnT - 13.9*lnRH + 0.14*moldWoodSpecies - 0.33*moldSurfaceQuality + 66.02)
+	tv := math.Exp(-0.74*lnT - 12.72*lnRH + 0.06*moldWoodSpecies + 61.50)
+
+	k1 := 1.0
+	if s.Index >= 1 {
+		k1 = 2 / (tv/tm - 1)
+	}
+	r := (critical - relativeHumid) / (critical - 100)
+	maxIndex := 1 + 7*r - 2*r*r
+	k2 := max(1-math.Exp(2.3*(s.Index-maxIndex)), 0)
+
+	perDay := k1 * k2 / (7 * tm)
+	s.Index = min(s.Index+perDay*hours/24, moldIndexMax)
+}
+
+// moldDeclineRate returns the decline of the index per hour after the conditions became unfavorable
+func moldDeclineRate(dry time.Duration) float64 {
+	switch {
+	case dry <= 6*time.Hour:
+		return 0.00133
+	case dry <= 24*time.Hour:
+		return 0
+	default:
+		return 0.000667
+	}
+}
@@ -0,0 +1,91 @@
+package weather
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMoldCriticalHumidity(t *testing.T) {
+	for _, tt := range []struct {
+		temp float64
+		want float64
+	}{
+		{5, 88.0},
+		{10, 82.0},
+		{20, 80.0},
+		{30, 80},
+	} {
+		got := MoldCriticalHumidity(tt.temp)
+		if math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("MoldCriticalHumidity(%v) failed: got:%v want:%v", tt.temp, got, tt.want)
+		}
+	}
+}
+
+func TestMoldState(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	var s MoldState
+	at := start
+	run := func(d time.Duration, temp, humid float64) {
+		for end := at.Add(d); at.Before(end); {
+			at = at.Add(10 * time.Minute)
+			s.Update(at, temp, humid)
+		}
+	}
+
+	s.Update(at, 25, 95)
+	// a week at 25°C 95%: index 1 takes 1.70 weeks
+	run(7*24*time.Hour, 25, 95)
+	if math.Abs(s.Index-0.587) > 0.01 {
+		t.Errorf("Index after a week failed: got:%v want:%v", s.Index, 0.587)
+	}
+
+	// dry conditions don't grow
+	run(24*time.Hour, 20, 60)
+	if math.Abs(s.Index-(0.587-0.00133*6)) > 0.01 {
+		t.Errorf("Index after a dry day failed: got:%v want:%v", s.Index, 0.587-0.00133*6)
+	}
+	if !s.DrySince.Equal(start.Add(7 * 24 * time.Hour)) {
+		t.Errorf("DrySince failed: got:%v want:%v", s.DrySince, start.Add(7*24*time.Hour))
+	}
+
+	// grows faster beyond index 1, up to the max at the humidity
+	run(60*24*time.Hour, 25, 95)
+	if !s.DrySince.IsZero() {
+		t.Errorf("DrySince while humid failed: got:%v", s.DrySince)
+	}
+	if s.Index < 4 || s.Index > 5.125 {
+		t.Errorf("Index after 2 months failed: got:%v want: 4 to 5.125", s.Index)
+	}
+
+	// gaps are skipped, neither grow nor decline
+	run(time.Hour, 20, 30)
+	if s.DrySince.IsZero() {
+		t.Errorf("DrySince before a gap failed: got:%v", s.DrySince)
+	}
+	before := s.Index
+	at = at.Add(30 * 24 * time.Hour)
+	s.Update(at, 20, 30)
+	if s.Index != before {
+		t.Errorf("Index over a gap failed: got:%v want:%v", s.Index, before)
+	}
+	if s.Skipped != 30*24*time.Hour {
+		t.Errorf("Skipped failed: got:%v want:%v", s.Skipped, 30*24*time.Hour)
+	}
+	if !s.DrySince.IsZero() || !s.UpdatedAt.Equal(at) {
+		t.Errorf("restart after a gap failed: DrySince:%v UpdatedAt:%v", s.DrySince, s.UpdatedAt)
+	}
+
+	// restarts declining at the first phase
+	run(time.Hour, 20, 30)
+	if got, want := before-s.Index, 0.00133; math.Abs(got-want) > 1e-9 {
+		t.Errorf("decline after a gap failed: got:%v want:%v", got, want)
+	}
+
+	// a gap of exactly the longest step is integrated
+	s.Update(at.Add(moldMaxStep), 20, 30)
+	if s.Skipped != 30*24*time.Hour {
+		t.Errorf("Skipped of the longest step failed: got:%v want:%v", s.Skipped, 30*24*time.Hour)
+	}
+}
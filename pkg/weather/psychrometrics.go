@@ -0,0 +1,76 @@
+package weather
+
+import (
+	"math"
+)
+
+// StandardPressure is the pressure of the standard atmosphere at sea level in hectopascals,
+// used by the pressure-aware functions when no barometer is available.
+const StandardPressure = 1013.25
+
+// ratio of molecular weights of water vapor and dry air, in g/kg
+const vaporDryAirRatio = 621.98
+
+// SaturationVaporPressure returns the saturation vapor pressure over water in hectopascals,
+// by the Magnus formula.
+func SaturationVaporPressure(temp float64) float64 {
+	return magnusE0 * math.Exp(magnusWaterA*temp/(magnusWaterB+temp))
+}
+
+// VaporPressure returns the vapor pressure in hectopascals
+func VaporPressure(temp, relativeHumid float64) float64 {
+	return SaturationVaporPressure(temp) * relativeHumid / 100
+}
+
+// VaporPressureAt returns the vapor pressure of moist air in hectopascals at the pressure,
+// corrected by the enhancement factor of the WMO.
+func VaporPressureAt(temp, relativeHumid, pressure float64) float64 {
+	enhancement := 1.0016 + 3.15e-6*pressure - 0.074/pressure
+	return enhancement * VaporPressure(temp, relativeHumid)
+}
+
+// MixingRatio returns the mass of water vapor per dry air in g/kg
+func MixingRatio(temp, relativeHumid, pressure float64) float64 {
+	e := VaporPressureAt(temp, relativeHumid, pressure)
+	return vaporDryAirRatio * e / (pressure - e)
+}
+
+// SpecificHumidity returns the mass of water vapor per moist air in g/kg
+func SpecificHumidity(temp, relativeHumid, pressure float64) float64 {
+	e := VaporPressureAt(temp, relativeHumid, pressure)
+	return vaporDryAirRatio * e / (pressure - 0.378*e)
+}
+
+// Enthalpy returns the specific enthalpy of moist air in kJ per kg of dry air
+func Enthalpy(temp, relativeHumid, pressure float64) float64 {
+	w := MixingRatio(temp, relativeHumid, pressure) / 1000
+	return 1.006*temp + w*(2501+1.86*temp)
+}
+
+// WetBulbStull returns the wet-bulb temperature by the empirical formula of Stull (2011),
+// valid at sea level pressure for relative humidity 5% to 99% and temperature -20 to 50°C.
+func WetBulbStull(temp, relativeHumid float64) float64 {
+	return temp*math.Atan(0.151977*math.Sqrt(relativeHumid+8.313659)) +
+		math.Atan(temp+relativeHumid) - math.Atan(relativeHumid-1.676331) +
+		0.00391838*math.Pow(relativeHumid, 1.5)*math.Atan(0.023101*relativeHumid) - 4.686035
+}
+
+// WetBulb returns the wet-bulb temperature at the pressure, solving the psychrometric
+// equation of a ventilated psychrometer iteratively.
+func WetBulb(temp, relativeHumid, pressure float64) float64 {
+	e := VaporPressureAt(temp, relativeHumid, pressure)
+	enhancement := 1.0016 + 3.15e-6*pressure - 0.074/pressure
+
+	// the vapor pressure by the psychrometric equation increases with wet-bulb temperature
+	lo, hi := temp-60, temp
+	for range 60 {
+		tw := (lo + hi) / 2
+		a := 6.53e-4 * (1 + 0.000944*tw)
+		if enhancement*SaturationVaporPressure(tw)-a*pressure*(temp-tw) > e {
+			hi = tw
+		} else {
+			lo = tw
+		}
+	}
+	return (lo + hi) / 2
+}
@@ -0,0 +1,45 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPsychrometrics(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		// psychrometric chart at sea level
+		{"SaturationVaporPressure(20)", SaturationVaporPressure(20), 23.37},
+		{"VaporPressure(20, 50)", VaporPressure(20, 50), 11.69},
+		{"MixingRatio(20, 50)", MixingRatio(20, 50, StandardPressure), 7.3},
+		{"SpecificHumidity(20, 50)", SpecificHumidity(20, 50, StandardPressure), 7.2},
+		{"Enthalpy(20, 50)", Enthalpy(20, 50, StandardPressure), 38.6},
+		{"MixingRatio(25, 60)", MixingRatio(25, 60, StandardPressure), 11.9},
+		{"Enthalpy(25, 60)", Enthalpy(25, 60, StandardPressure), 55.5},
+		// moist air holds more vapor per dry air at lower pressure
+		{"MixingRatio(20, 50) at 850hPa", MixingRatio(20, 50, 850), 8.7},
+		// Stull (2011)
+		{"WetBulbStull(20, 50)", WetBulbStull(20, 50), 13.7},
+		{"WetBulb(20, 50)", WetBulb(20, 50, StandardPressure), 13.8},
+		{"WetBulb(30, 100)", WetBulb(30, 100, StandardPressure), 30.0},
+	} {
+		if math.Abs(tt.got-tt.want) > 0.1 {
+			t.Errorf("%s failed: got:%v want:%v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// the wet-bulb is lower at lower pressure
+	if sea, high := WetBulb(25, 40, StandardPressure), WetBulb(25, 40, 850); high >= sea {
+		t.Errorf("WetBulb at 850hPa failed: got:%v want: below %v", high, sea)
+	}
+	// both methods agree in the range of the Stull formula
+	for _, c := range [][2]float64{{10, 80}, {15, 30}, {25, 60}, {35, 20}} {
+		stull, iter := WetBulbStull(c[0], c[1]), WetBulb(c[0], c[1], StandardPressure)
+		if math.Abs(stull-iter) > 0.5 {
+			t.Errorf("WetBulb(%v, %v) failed: Stull:%v iterative:%v", c[0], c[1], stull, iter)
+		}
+	}
+}
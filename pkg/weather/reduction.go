@@ -0,0 +1,212 @@
+package weather
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// constants of the standard atmosphere
+const (
+	standardTemperature = 288.15
+	lapseRate           = 0.0065
+	gravity             = 9.80665
+	dryAirGasConstant   = 287.05
+	// exponent of the barometric formula, R*L/(g*M)
+	barometricExponent = 0.190263
+)
+
+// MeanTemperaturePeriod is the period of the mean temperature used by the hypsometric reduction
+const MeanTemperaturePeriod = 12 * time.Hour
+
+// QNH reduces the station pressure in hectopascals at the height in meters
+// to sea level by the standard atmosphere, as the altimeter setting.
+func QNH(stationPressure, height float64) float64 {
+	if height <= 0 {
+		return stationPressure
+	}
+	return stationPressure * math.Pow(1-lapseRate*height/standardTemperature, -1/barometricExponent)
+}
+
+// HypsometricSeaLevelPressure reduces the station pressure in hectopascals at the height in meters
+// to sea level by the hypsometric equation, assuming the air column of meanTemp at the station
+// with the standard lapse rate.
+func HypsometricSeaLevelPressure(stationPressure, meanTemp, height float64) float64 {
+	return hypsometric(stationPressure, meanTemp+273.15, height)
+}
+
+// HumidityCorrectedSeaLevelPressure reduces as HypsometricSeaLevelPressure
+// by the virtual temperature of the moist air.
+func HumidityCorrectedSeaLevelPressure(stationPressure, meanTemp, vaporPressure, height float64) float64 {
+	return hypsometric(stationPressure, VirtualTemperature(meanTemp, vaporPressure, stationPressure), height)
+}
+
+func hypsometric(stationPressure, kelvin, height float64) float64 {
+	if height <= 0 {
+		return stationPressure
+	}
+	// mean temperature of the air column between the station and sea level
+	columnMean := kelvin + lapseRate*height/2
+	return stationPressure * math.Exp(gravity*height/(dryAirGasConstant*columnMean))
+}
+
+// VirtualTemperature returns the virtual temperature in kelvin of moist air
+// by vapor pressure and pressure in hectopascals
+func VirtualTemperature(temp, vaporPressure, pressure float64) float64 {
+	return (temp + 273.15) / (1 - (1-vaporDryAirRatio/1000)*vaporPressure/pressure)
+}
+
+// AltitudeFromPressure returns the height in meters where the pressure is observed,
+// by the standard atmosphere and the sea level pressure
+func AltitudeFromPressure(pressure, seaLevelPressure float64) float64 {
+	return standardTemperature / lapseRate * (1 - math.Pow(pressure/seaLevelPressure, barometricExponent))
+}
+
+// Reduction is a method to reduce station pressure to sea level
+type Reduction int
+
+const (
+	// ReductionCurrent uses MeanHeightAirPressure with the current temperature
+	ReductionCurrent Reduction = iota
+	// ReductionQNH uses the standard atmosphere
+	ReductionQNH
+	// ReductionHypsometric uses the mean temperature of MeanTemperaturePeriod
+	ReductionHypsometric
+	// ReductionHumidity uses the virtual temperature by the mean temperature and the current humidity
+	ReductionHumidity
+)
+
+var reductionNames = []string{"current", "qnh", "hypsometric", "humidity"}
+
+func (r Reduction) String() string {
+	if r < 0 || int(r) >= len(reductionNames) {
+		return fmt.Sprintf("Reduction(%d)", int(r))
+	}
+	return reductionNames[r]
+}
+
+// Set parses the name of reduction. It satisfies flag.Value.
+func (r *Reduction) Set(s string) error {
+	for i, name := range reductionNames {
+		if strings.EqualFold(s, name) {
+			*r = Reduction(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown reduction: %q (%s)", s, strings.Join(reductionNames, ","))
+}
+
+// reductionFlag accepts the offered methods only
+type reductionFlag struct {
+	r       *Reduction
+	methods []Reduction
+}
+
+func (f reductionFlag) String() string {
+	if f.r == nil {
+		return ""
+	}
+	return f.r.String()
+}
+
+func (f reductionFlag) Set(s string) error {
+	var r Reduction
+	if err := r.Set(s); err != nil {
+		return err
+	}
+	if !slices.Contains(f.methods, r) {
+		return fmt.Errorf("reduction not available: %q (%s)", s, reductionList(f.methods))
+	}
+	*f.r = r
+	return nil
+}
+
+func reductionList(methods []Reduction) string {
+	var names []string
+	for _, m := range methods {
+		names = append(names, m.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// RegisterReductionFlag registers flag `reduction` to fs, and returns the reduction configured by it.
+// It offers methods, or all the methods if none is given. As hypsometric and humidity average
+// the temperature measured by the probe, do not offer them if the probe is indoors.
+func RegisterReductionFlag(fs *flag.FlagSet, methods ...Reduction) *Reduction {
+	if len(methods) == 0 {
+		methods = []Reduction{ReductionCurrent, ReductionQNH, ReductionHypsometric, ReductionHumidity}
+	}
+	usage := "Method to reduce pressure to sea level: " + reductionList(methods)
+	if slices.Contains(methods, ReductionHypsometric) || slices.Contains(methods, ReductionHumidity) {
+		usage += " (hypsometric and humidity average the temperature of the probe, so that the probe must be outdoors)"
+	}
+
+	r := ReductionCurrent
+	fs.Var(reductionFlag{&r, methods}, "reduction", usage)
+	return &r
+}
+
+type temperatureSample struct {
+	at   time.Time
+	temp float64
+}
+
+// SeaLevelReducer reduces station pressure to sea level by the method,
+// keeping the temperature history for the mean. It is safe for concurrent use.
+type SeaLevelReducer struct {
+	mu      sync.Mutex
+	method  Reduction
+	height  float64
+	samples []temperatureSample
+}
+
+// NewSeaLevelReducer creates a reducer at the height in meters
+func NewSeaLevelReducer(method Reduction, height float64) *SeaLevelReducer {
+	return &SeaLevelReducer{
+		method: method,
+		height: height,
+	}
+}
+
+// meanTemperature adds the temperature and returns the mean of MeanTemperaturePeriod. guarded by mu
+func (r *SeaLevelReducer) meanTemperature(at time.Time, temp float64) float64 {
+	if n := len(r.samples); n == 0 || at.After(r.samples[n-1].at) {
+		r.samples = append(r.samples, temperatureSample{at, temp})
+	}
+	start := at.Add(-MeanTemperaturePeriod)
+	i := 0
+	for i < len(r.samples)-1 && r.samples[i].at.Before(start) {
+		i++
+	}
+	r.samples = r.samples[i:]
+
+	var sum float64
+	for _, s := range r.samples {
+		sum += s.temp
+	}
+	return sum / float64(len(r.samples))
+}
+
+// Reduce reduces the station pressure in hectopascals observed at the time with temperature
+// and relative humidity. relativeHumid is NaN if unknown, then the humidity is not corrected.
+func (r *SeaLevelReducer) Reduce(at time.Time, stationPressure, temp, relativeHumid float64) float64 {
+	switch r.method {
+	case ReductionQNH:
+		return QNH(stationPressure, r.height)
+	case ReductionHypsometric, ReductionHumidity:
+		r.mu.Lock()
+		mean := r.meanTemperature(at, temp)
+		r.mu.Unlock()
+		if r.method == ReductionHumidity && !math.IsNaN(relativeHumid) {
+			e := VaporPressure(temp, relativeHumid)
+			return HumidityCorrectedSeaLevelPressure(stationPressure, mean, e, r.height)
+		}
+		return HypsometricSeaLevelPressure(stationPressure, mean, r.height)
+	default:
+		return MeanHeightAirPressure(stationPressure, temp, r.height)
+	}
+}
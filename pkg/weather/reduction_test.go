@@ -0,0 +1,103 @@
+package weather
+
+import (
+	"flag"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSeaLevelPressure(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"QNH(1000, 0)", QNH(1000, 0), 1000},
+		{"QNH(1000, 100)", QNH(1000, 100), 1011.9},
+		{"QNH(900, 1000)", QNH(900, 1000), 1014.7},
+		{"HypsometricSeaLevelPressure(1000, 15, 100)", HypsometricSeaLevelPressure(1000, 15, 100), 1011.9},
+		{"HypsometricSeaLevelPressure(1000, -10, 100)", HypsometricSeaLevelPressure(1000, -10, 100), 1013.0},
+		{"HumidityCorrectedSeaLevelPressure(1000, 15, 0, 100)", HumidityCorrectedSeaLevelPressure(1000, 15, 0, 100), 1011.9},
+		{"VirtualTemperature(30, 30, 1000)", VirtualTemperature(30, 30, 1000), 306.6},
+		{"AltitudeFromPressure(1000, 1013.25)", AltitudeFromPressure(1000, 1013.25), 110.9},
+		{"AltitudeFromPressure(898.75, 1013.25)", AltitudeFromPressure(898.75, 1013.25), 1000},
+	} {
+		if math.Abs(tt.got-tt.want) > 0.1*math.Max(1, math.Abs(tt.want)/1000) {
+			t.Errorf("%s failed: got:%v want:%v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// round trip by the standard atmosphere
+	if got := AltitudeFromPressure(950, QNH(950, 500)); math.Abs(got-500) > 0.1 {
+		t.Errorf("AltitudeFromPressure of QNH failed: got:%v want:%v", got, 500)
+	}
+	// moist air is lighter
+	if dry, moist := HypsometricSeaLevelPressure(1000, 30, 100), HumidityCorrectedSeaLevelPressure(1000, 30, 30, 100); moist >= dry {
+		t.Errorf("HumidityCorrectedSeaLevelPressure failed: got:%v want: below %v", moist, dry)
+	}
+}
+
+func TestReductionFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	r := RegisterReductionFlag(fs)
+
+	if *r != ReductionCurrent {
+		t.Errorf("default failed: got:%v want:%v", *r, ReductionCurrent)
+	}
+	if err := fs.Parse([]string{"--reduction", "QNH"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if *r != ReductionQNH {
+		t.Errorf("Parse() failed: got:%v want:%v", *r, ReductionQNH)
+	}
+	if err := fs.Parse([]string{"--reduction", "isa"}); err == nil {
+		t.Errorf("Parse() of unknown reduction should fail")
+	}
+
+	// indoor probes offer methods not by the temperature
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	r = RegisterReductionFlag(fs, ReductionCurrent, ReductionQNH)
+	if err := fs.Parse([]string{"--reduction", "hypsometric"}); err == nil {
+		t.Errorf("Parse() of reduction not offered should fail")
+	}
+	if err := fs.Parse([]string{"--reduction", "qnh"}); err != nil || *r != ReductionQNH {
+		t.Errorf("Parse() failed: got:%v want:%v err:%v", *r, ReductionQNH, err)
+	}
+}
+
+func TestSeaLevelReducer(t *testing.T) {
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	r := NewSeaLevelReducer(ReductionHypsometric, 100)
+	// the temperature swings daily
+	for i := range 13 {
+		r.Reduce(start.Add(time.Duration(i)*time.Hour), 1000, 10+float64(i), math.NaN())
+	}
+	// mean of 12 hours, from 11 to 23 degrees
+	got := r.Reduce(start.Add(13*time.Hour), 1000, 23, math.NaN())
+	if want := HypsometricSeaLevelPressure(1000, 17, 100); got != want {
+		t.Errorf("Reduce() by hypsometric failed: got:%v want:%v", got, want)
+	}
+
+	r = NewSeaLevelReducer(ReductionHumidity, 100)
+	got = r.Reduce(start, 1000, 20, 60)
+	if want := HumidityCorrectedSeaLevelPressure(1000, 20, VaporPressure(20, 60), 100); got != want {
+		t.Errorf("Reduce() by humidity failed: got:%v want:%v", got, want)
+	}
+
+	for _, tt := range []struct {
+		method Reduction
+		want   float64
+	}{
+		{ReductionCurrent, MeanHeightAirPressure(1000, 20, 100)},
+		{ReductionQNH, QNH(1000, 100)},
+	} {
+		if got := NewSeaLevelReducer(tt.method, 100).Reduce(start, 1000, 20, 60); got != tt.want {
+			t.Errorf("Reduce() by %v failed: got:%v want:%v", tt.method, got, tt.want)
+		}
+	}
+}
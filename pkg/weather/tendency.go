@@ -0,0 +1,258 @@
+package weather
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TendencyPeriod is the period of the pressure tendency
+const TendencyPeriod = 3 * time.Hour
+
+// changes less than trendSteady over TendencyPeriod are steady, as the Met Office
+// calls them steady or slow
+const trendSteady = 1.6
+
+// changes less than characteristicSteady in each half of TendencyPeriod are steady,
+// above the noise of barometers
+const characteristicSteady = 0.2
+
+// PressureTrend is the direction of the pressure tendency
+type PressureTrend int
+
+// trends over TendencyPeriod
+const (
+	PressureFalling PressureTrend = iota
+	PressureSteady
+	PressureRising
+)
+
+// PressureTrendNames are the names of the trends in order
+var PressureTrendNames = []string{"falling", "steady", "rising"}
+
+func (t PressureTrend) String() string {
+	if t < 0 || int(t) >= len(PressureTrendNames) {
+		return "unknown"
+	}
+	return PressureTrendNames[t]
+}
+
+// PressureTendency is the change of pressure over TendencyPeriod
+type PressureTendency struct {
+	// Change is the pressure change in hectopascals
+	Change float64
+	// Characteristic is the code of WMO code table 0200, from 0 to 8
+	Characteristic int
+	Trend          PressureTrend
+}
+
+type pressureSample struct {
+	at       time.Time
+	pressure float64
+}
+
+// PressureHistory keeps the pressure samples of TendencyPeriod. It is safe for concurrent use.
+type PressureHistory struct {
+	mu      sync.Mutex
+	samples []pressureSample
+}
+
+// NewPressureHistory creates an empty history
+func NewPressureHistory() *PressureHistory {
+	return &PressureHistory{}
+}
+
+// Add adds the pressure in hectopascals at the time. Samples older than the latest are ignored.
+func (h *PressureHistory) Add(at time.Time, pressure float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if n := len(h.samples); n > 0 && !at.After(h.samples[n-1].at) {
+		return
+	}
+	h.samples = append(h.samples, pressureSample{at, pressure})
+
+	// keep the last sample at or before the start of the period
+	start := at.Add(-TendencyPeriod)
+	i := 0
+	for i+1 < len(h.samples) && !h.samples[i+1].at.After(start) {
+		i++
+	}
+	h.samples = h.samples[i:]
+}
+
+// pressureAt interpolates the pressure at the time. guarded by mu
+func (h *PressureHistory) pressureAt(at time.Time) float64 {
+	for i := len(h.samples) - 1; i > 0; i-- {
+		prev, next := h.samples[i-1], h.samples[i]
+		if !prev.at.After(at) {
+			ratio := float64(at.Sub(prev.at)) / float64(next.at.Sub(prev.at))
+			return prev.pressure + (next.pressure-prev.pressure)*ratio
+		}
+	}
+	return h.samples[0].pressure
+}
+
+// Tendency returns the tendency over TendencyPeriod up to the latest sample.
+// It returns false until the history covers the period.
+func (h *PressureHistory) Tendency() (PressureTendency, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.samples) < 2 {
+		return PressureTendency{}, false
+	}
+	latest := h.samples[len(h.samples)-1]
+	start := latest.at.Add(-TendencyPeriod)
+	if h.samples[0].at.After(start) {
+		return PressureTendency{}, false
+	}
+
+	first := h.pressureAt(start)
+	middle := h.pressureAt(latest.at.Add(-TendencyPeriod / 2))
+	change := latest.pressure - first
+
+	trend := PressureSteady
+	if change >= trendSteady {
+		trend = PressureRising
+	} else if change <= -trendSteady {
+		trend = PressureFalling
+	}
+
+	return PressureTendency{
+		Change:         change,
+		Characteristic: PressureCharacteristic(middle-first, latest.pressure-middle),
+		Trend:          trend,
+	}, true
+}
+
+func steadySign(change float64) int {
+	switch {
+	case change >= characteristicSteady:
+		return 1
+	case change <= -characteristicSteady:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// PressureCharacteristic returns the code of WMO code table 0200 by the pressure changes
+// in the former and the latter halves of the period.
+func PressureCharacteristic(former, latter float64) int {
+	total := steadySign(former + latter)
+	s1, s2 := steadySign(former), steadySign(latter)
+
+	switch {
+	case total == 0 && s1 > 0 && s2 < 0:
+		// increasing, then decreasing; the same as before
+		return 0
+	case total == 0 && s1 < 0 && s2 > 0:
+		// decreasing, then increasing; the same as before
+		return 5
+	case total == 0:
+		// steady
+		return 4
+	case total > 0:
+		switch {
+		case s1 > 0 && s2 < 0:
+			// increasing, then decreasing; higher than before
+			return 0
+		case s1 > 0 && s2 == 0, s1 > 0 && latter < former/2:
+			// increasing, then steady or increasing more slowly
+			return 1
+		case s1 <= 0, latter > former*2:
+			// decreasing or steady, then increasing; or increasing more rapidly
+			return 3
+		default:
+			// increasing steadily
+			return 2
+		}
+	default:
+		switch {
+		case s1 < 0 && s2 > 0:
+			// decreasing, then increasing; lower than before
+			return 5
+		case s1 < 0 && s2 == 0, s1 < 0 && latter > former/2:
+			// decreasing, then steady or decreasing more slowly
+			return 6
+		case s1 >= 0, latter < former*2:
+			// increasing or steady, then decreasing; or decreasing more rapidly
+			return 8
+		default:
+			// decreasing steadily
+			return 7
+		}
+	}
+}
+
+// Zambretti is a forecast of the Zambretti forecaster
+type Zambretti struct {
+	// Number is from 1 to 32, by the trend and the pressure
+	Number int
+	Letter string
+	Text   string
+}
+
+var zambrettiTexts = map[string]string{
+	"A": "Settled fine",
+	"B": "Fine weather",
+	"C": "Becoming fine",
+	"D": "Fine, becoming less settled",
+	"E": "Fine, possible showers",
+	"F": "Fairly fine, improving",
+	"G": "Fairly fine, possible showers early",
+	"H": "Fairly fine, showery later",
+	"I": "Showery early, improving",
+	"J": "Changeable, mending",
+	"K": "Fairly fine, showers likely",
+	"L": "Rather unsettled clearing later",
+	"M": "Unsettled, probably improving",
+	"N": "Showery, bright intervals",
+	"O": "Showery, becoming less settled",
+	"P": "Changeable, some rain",
+	"Q": "Unsettled, short fine intervals",
+	"R": "Unsettled, rain later",
+	"S": "Unsettled, some rain",
+	"T": "Mostly very unsettled",
+	"U": "Occasional rain, worsening",
+	"V": "Rain at times, very unsettled",
+	"W": "Rain at frequent intervals",
+	"X": "Rain, very unsettled",
+	"Y": "Stormy, may improve",
+	"Z": "Stormy, much rain",
+}
+
+// letters of the forecast numbers of each trend, from the highest pressure
+var zambrettiLetters = map[PressureTrend]struct {
+	first   int
+	letters string
+}{
+	PressureFalling: {1, "ABDHORUXZ"},
+	PressureSteady:  {10, "ABEKNPSWXZ"},
+	PressureRising:  {20, "ABCFGIJLMQTYZ"},
+}
+
+// ZambrettiForecast returns the forecast by the sea level pressure in hectopascals and its trend.
+// The adjustments by wind direction and season are not applied.
+func ZambrettiForecast(seaLevelPressure float64, trend PressureTrend) Zambretti {
+	var z float64
+	switch trend {
+	case PressureFalling:
+		z = 127 - 0.12*seaLevelPressure
+	case PressureRising:
+		z = 185 - 0.16*seaLevelPressure
+	default:
+		trend = PressureSteady
+		z = 144 - 0.13*seaLevelPressure
+	}
+
+	l := zambrettiLetters[trend]
+	i := min(max(int(math.Round(z))-l.first, 0), len(l.letters)-1)
+	letter := l.letters[i : i+1]
+	return Zambretti{
+		Number: l.first + i,
+		Letter: letter,
+		Text:   zambrettiTexts[letter],
+	}
+}
@@ -0,0 +1,115 @@
+package weather
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPressureHistory(t *testing.T) {
+	h := NewPressureHistory()
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	// falls 0.5hPa per 30 minutes
+	for i := range 6 {
+		h.Add(start.Add(time.Duration(i)*30*time.Minute), 1013-0.5*float64(i))
+	}
+	if _, ok := h.Tendency(); ok {
+		t.Errorf("Tendency() before the period should not be ok")
+	}
+
+	for i := 6; i <= 10; i++ {
+		h.Add(start.Add(time.Duration(i)*30*time.Minute), 1013-0.5*float64(i))
+	}
+	// out of order
+	h.Add(start, 900)
+
+	got, ok := h.Tendency()
+	if !ok {
+		t.Fatalf("Tendency() failed")
+	}
+	if math.Abs(got.Change-(-3)) > 1e-9 {
+		t.Errorf("Change failed: got:%v want:%v", got.Change, -3)
+	}
+	if got.Characteristic != 7 {
+		t.Errorf("Characteristic failed: got:%v want:%v", got.Characteristic, 7)
+	}
+	if got.Trend != PressureFalling {
+		t.Errorf("Trend failed: got:%v want:%v", got.Trend, PressureFalling)
+	}
+
+	// old samples are dropped
+	if n := len(h.samples); n != 7 {
+		t.Errorf("samples failed: got:%v want:%v", n, 7)
+	}
+}
+
+func TestPressureHistoryInterpolate(t *testing.T) {
+	h := NewPressureHistory()
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	h.Add(start, 1000)
+	h.Add(start.Add(2*time.Hour), 1002)
+	h.Add(start.Add(4*time.Hour), 1004)
+
+	got, ok := h.Tendency()
+	if !ok {
+		t.Fatalf("Tendency() failed")
+	}
+	if math.Abs(got.Change-3) > 1e-9 {
+		t.Errorf("Change failed: got:%v want:%v", got.Change, 3)
+	}
+	if got.Trend != PressureRising {
+		t.Errorf("Trend failed: got:%v want:%v", got.Trend, PressureRising)
+	}
+}
+
+func TestPressureCharacteristic(t *testing.T) {
+	for _, tt := range []struct {
+		former, latter float64
+		want           int
+	}{
+		{1.0, -0.5, 0},
+		{1.0, -1.0, 0},
+		{1.0, 0.1, 1},
+		{1.0, 0.3, 1},
+		{1.0, 1.0, 2},
+		{0.0, 1.0, 3},
+		{0.3, 1.0, 3},
+		{0.1, -0.1, 4},
+		{-1.0, 1.0, 5},
+		{-1.0, 0.5, 5},
+		{-1.0, -0.1, 6},
+		{-1.0, -0.3, 6},
+		{-1.0, -1.0, 7},
+		{0.0, -1.0, 8},
+		{-0.3, -1.0, 8},
+	} {
+		if got := PressureCharacteristic(tt.former, tt.latter); got != tt.want {
+			t.Errorf("PressureCharacteristic(%v, %v) failed: got:%v want:%v", tt.former, tt.latter, got, tt.want)
+		}
+	}
+}
+
+func TestZambrettiForecast(t *testing.T) {
+	for _, tt := range []struct {
+		pressure float64
+		trend    PressureTrend
+		want     Zambretti
+	}{
+		{1040, PressureFalling, Zambretti{2, "B", "Fine weather"}},
+		{1000, PressureFalling, Zambretti{7, "U", "Occasional rain, worsening"}},
+		{960, PressureFalling, Zambretti{9, "Z", "Stormy, much rain"}},
+		{1030, PressureSteady, Zambretti{10, "A", "Settled fine"}},
+		{1013, PressureSteady, Zambretti{12, "E", "Fine, possible showers"}},
+		{990, PressureSteady, Zambretti{15, "P", "Changeable, some rain"}},
+		{1030, PressureRising, Zambretti{20, "A", "Settled fine"}},
+		{1013, PressureRising, Zambretti{23, "F", "Fairly fine, improving"}},
+		{980, PressureRising, Zambretti{28, "M", "Unsettled, probably improving"}},
+		{940, PressureRising, Zambretti{32, "Z", "Stormy, much rain"}},
+	} {
+		if got := ZambrettiForecast(tt.pressure, tt.trend); got != tt.want {
+			t.Errorf("ZambrettiForecast(%v, %v) failed: got:%+v want:%+v", tt.pressure, tt.trend, got, tt.want)
+		}
+	}
+}
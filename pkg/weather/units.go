@@ -0,0 +1,42 @@
+package weather
+
+// hectopascals per unit
+const (
+	hPaPerInchOfMercury       = 33.8638866667
+	hPaPerMillimeterOfMercury = 1.33322387415
+)
+
+// grams per grain, cubic meters per cubic foot
+const (
+	gramsPerGrain       = 0.06479891
+	cubicMetersPerFoot3 = 0.028316846592
+)
+
+func CelsiusToFahrenheit(temp float64) float64 {
+	return temp*9/5 + 32
+}
+
+func CelsiusToKelvin(temp float64) float64 {
+	return temp + 273.15
+}
+
+func HectopascalsToPascals(pressure float64) float64 {
+	return pressure * 100
+}
+
+func HectopascalsToKilopascals(pressure float64) float64 {
+	return pressure / 10
+}
+
+func HectopascalsToInchesOfMercury(pressure float64) float64 {
+	return pressure / hPaPerInchOfMercury
+}
+
+func HectopascalsToMillimetersOfMercury(pressure float64) float64 {
+	return pressure / hPaPerMillimeterOfMercury
+}
+
+// GramsPerCubicMeterToGrainsPerCubicFoot converts absolute humidity
+func GramsPerCubicMeterToGrainsPerCubicFoot(humid float64) float64 {
+	return humid * cubicMetersPerFoot3 / gramsPerGrain
+}
@@ -0,0 +1,56 @@
+package weather
+
+// VaporPressureDeficit returns the vapor pressure deficit of air in kilopascals
+func VaporPressureDeficit(temp, relativeHumid float64) float64 {
+	return LeafVaporPressureDeficit(temp, relativeHumid, 0)
+}
+
+// LeafVaporPressureDeficit returns the vapor pressure deficit between the leaf
+// and the air in kilopascals. leafOffset is the leaf temperature relative to the air,
+// usually 1 to 3 degrees below under lights.
+func LeafVaporPressureDeficit(temp, relativeHumid, leafOffset float64) float64 {
+	deficit := SaturationVaporPressure(temp+leafOffset) - VaporPressure(temp, relativeHumid)
+	return HectopascalsToKilopascals(deficit)
+}
+
+// VPDStage is a range of vapor pressure deficit suited for a growth stage of plants
+type VPDStage int
+
+const (
+	// VPDTooLow is below 0.4kPa, risking mold and disease
+	VPDTooLow VPDStage = iota
+	// VPDPropagation is 0.4 to 0.8kPa, for clones, seedlings and early vegetative growth
+	VPDPropagation
+	// VPDVegetative is 0.8 to 1.2kPa, for late vegetative growth and early flowering
+	VPDVegetative
+	// VPDFlowering is 1.2 to 1.6kPa, for mid to late flowering
+	VPDFlowering
+	// VPDTooHigh is 1.6kPa or above, stressing plants
+	VPDTooHigh
+)
+
+// VPDStageNames are the names of the stages in order
+var VPDStageNames = []string{"too_low", "propagation", "vegetative", "flowering", "too_high"}
+
+func (s VPDStage) String() string {
+	if s < 0 || int(s) >= len(VPDStageNames) {
+		return "unknown"
+	}
+	return VPDStageNames[s]
+}
+
+// VPDStageOf classifies vapor pressure deficit in kilopascals
+func VPDStageOf(vpd float64) VPDStage {
+	switch {
+	case vpd >= 1.6:
+		return VPDTooHigh
+	case vpd >= 1.2:
+		return VPDFlowering
+	case vpd >= 0.8:
+		return VPDVegetative
+	case vpd >= 0.4:
+		return VPDPropagation
+	default:
+		return VPDTooLow
+	}
+}
@@ -0,0 +1,54 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVaporPressureDeficit(t *testing.T) {
+	for _, tt := range []struct {
+		temp, humid, leafOffset float64
+		want                    float64
+	}{
+		// VPD charts of air
+		{20, 50, 0, 1.17},
+		{25, 60, 0, 1.27},
+		{30, 70, 0, 1.27},
+		{25, 100, 0, 0},
+		// leaf cooler than air
+		{25, 60, -2, 0.91},
+		{25, 60, 2, 1.66},
+	} {
+		got := LeafVaporPressureDeficit(tt.temp, tt.humid, tt.leafOffset)
+		if math.Abs(got-tt.want) > 0.02 {
+			t.Errorf("LeafVaporPressureDeficit(%v, %v, %v) failed: got:%v want:%v", tt.temp, tt.humid, tt.leafOffset, got, tt.want)
+		}
+	}
+
+	if got, want := VaporPressureDeficit(25, 60), LeafVaporPressureDeficit(25, 60, 0); got != want {
+		t.Errorf("VaporPressureDeficit(25, 60) failed: got:%v want:%v", got, want)
+	}
+}
+
+func TestVPDStage(t *testing.T) {
+	for _, tt := range []struct {
+		vpd  float64
+		want VPDStage
+	}{
+		{0.2, VPDTooLow},
+		{0.4, VPDPropagation},
+		{0.79, VPDPropagation},
+		{0.8, VPDVegetative},
+		{1.2, VPDFlowering},
+		{1.59, VPDFlowering},
+		{1.6, VPDTooHigh},
+	} {
+		if got := VPDStageOf(tt.vpd); got != tt.want {
+			t.Errorf("VPDStageOf(%v) failed: got:%v want:%v", tt.vpd, got, tt.want)
+		}
+	}
+
+	if got, want := VPDFlowering.String(), "flowering"; got != want {
+		t.Errorf("String() failed: got:%q want:%q", got, want)
+	}
+}